@@ -16,11 +16,27 @@ package app
 import (
 	log "github.com/Sirupsen/logrus"
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/commands"
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/config"
 	"github.com/urfave/cli"
 )
 
+// loadConfig is overridden in tests to supply the output preferences.
+var loadConfig = func() (*config.CliConfig, error) {
+	rdwr, err := config.NewReadWriter()
+	if err != nil {
+		return nil, err
+	}
+	return rdwr.GetConfig()
+}
+
 // BeforeApp is an action that is executed before any cli command.
 func BeforeApp(c *cli.Context) error {
+	// Loading the config logs nothing, so an unreadable config is reported only once, by the
+	// command itself. The verbose flag is checked afterwards so that it takes precedence over
+	// the quiet preference.
+	if ecsConfig, err := loadConfig(); err == nil {
+		ecsConfig.ApplyOutputPreferences()
+	}
 	if c.GlobalBool(command.VerboseFlag) || c.Bool(command.VerboseFlag) {
 		log.SetLevel(log.DebugLevel)
 	}
@@ -14,23 +14,129 @@
 package app
 
 import (
+	"bytes"
+	"errors"
 	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	log "github.com/Sirupsen/logrus"
 	command "github.com/aws/amazon-ecs-cli/ecs-cli/modules/commands"
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/config"
+	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli"
 )
 
 func TestBeforeApp(t *testing.T) {
-	flagSet := flag.NewFlagSet("ecs-cli", 0)
-	flagSet.Bool(command.VerboseFlag, true, "")
-	cliContext := cli.NewContext(nil, flagSet, nil)
+	defer setupConfig(config.NewCliConfig(""))()
+	defer resetLogging()
 
-	BeforeApp(cliContext)
+	BeforeApp(newContext(true))
 
 	observedLogLevel := log.GetLevel()
 	if log.DebugLevel != observedLogLevel {
 		t.Errorf("Log level was supposed to be set to debug. Expected [%s] Got [%s]", log.DebugLevel, observedLogLevel)
 	}
 }
+
+func TestBeforeAppAppliesOutputPreferences(t *testing.T) {
+	ecsConfig := config.NewCliConfig("")
+	ecsConfig.Color = config.ColorNever
+	ecsConfig.Quiet = true
+	defer setupConfig(ecsConfig)()
+	defer resetLogging()
+
+	BeforeApp(newContext(false))
+
+	assert.Equal(t, log.ErrorLevel, log.GetLevel(), "Expected quiet to suppress informational output and warnings")
+	formatter, ok := log.StandardLogger().Formatter.(*log.TextFormatter)
+	if assert.True(t, ok, "Expected a text formatter") {
+		assert.True(t, formatter.DisableColors, "Expected colors to be disabled")
+		assert.False(t, formatter.ForceColors, "Expected colors not to be forced")
+	}
+}
+
+func TestBeforeAppWithColorAlways(t *testing.T) {
+	ecsConfig := config.NewCliConfig("")
+	ecsConfig.Color = config.ColorAlways
+	defer setupConfig(ecsConfig)()
+	defer resetLogging()
+
+	BeforeApp(newContext(false))
+
+	assert.Equal(t, log.InfoLevel, log.GetLevel(), "Expected informational output without quiet")
+	formatter, ok := log.StandardLogger().Formatter.(*log.TextFormatter)
+	if assert.True(t, ok, "Expected a text formatter") {
+		assert.True(t, formatter.ForceColors, "Expected colors to be forced")
+		assert.False(t, formatter.DisableColors, "Expected colors not to be disabled")
+	}
+}
+
+func TestBeforeAppVerboseOverridesQuiet(t *testing.T) {
+	ecsConfig := config.NewCliConfig("")
+	ecsConfig.Quiet = true
+	defer setupConfig(ecsConfig)()
+	defer resetLogging()
+
+	BeforeApp(newContext(true))
+
+	assert.Equal(t, log.DebugLevel, log.GetLevel(), "Expected the verbose flag to take precedence over quiet")
+}
+
+func TestBeforeAppWithUnreadableConfig(t *testing.T) {
+	oldLoadConfig := loadConfig
+	loadConfig = func() (*config.CliConfig, error) {
+		return nil, errors.New("something failed")
+	}
+	defer func() { loadConfig = oldLoadConfig }()
+	defer resetLogging()
+
+	err := BeforeApp(newContext(false))
+
+	assert.NoError(t, err, "Expected the command to report an unreadable config")
+	assert.Equal(t, log.InfoLevel, log.GetLevel(), "Expected the default log level")
+}
+
+func TestBeforeAppWithMissingConfigHomeLogsNothing(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "test")
+	assert.NoError(t, err, "Error creating a temporary directory")
+	defer os.RemoveAll(tempDirName)
+
+	// The parent of the config home does not exist, so the config can not be read.
+	os.Setenv(command.ConfigHomeEnvVar, filepath.Join(tempDirName, "missing", "home"))
+	defer os.Unsetenv(command.ConfigHomeEnvVar)
+
+	var logOutput bytes.Buffer
+	log.SetOutput(&logOutput)
+	defer log.SetOutput(os.Stderr)
+	defer resetLogging()
+
+	err = BeforeApp(newContext(false))
+
+	assert.NoError(t, err, "Expected the command to report an unreadable config")
+	assert.Empty(t, logOutput.String(), "Expected nothing to be logged for an unreadable config")
+}
+
+// newContext returns a cli context with the verbose flag set to the given value.
+func newContext(verbose bool) *cli.Context {
+	flagSet := flag.NewFlagSet("ecs-cli", 0)
+	flagSet.Bool(command.VerboseFlag, verbose, "")
+	return cli.NewContext(nil, flagSet, nil)
+}
+
+// setupConfig makes BeforeApp read the given config and returns a func that restores the original.
+func setupConfig(ecsConfig *config.CliConfig) func() {
+	oldLoadConfig := loadConfig
+	loadConfig = func() (*config.CliConfig, error) {
+		return ecsConfig, nil
+	}
+	return func() { loadConfig = oldLoadConfig }
+}
+
+// resetLogging restores the default logrus level and formatter.
+func resetLogging() {
+	log.SetLevel(log.InfoLevel)
+	log.SetFormatter(&log.TextFormatter{})
+}
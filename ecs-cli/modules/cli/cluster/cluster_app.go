@@ -17,6 +17,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -38,6 +39,9 @@ import (
 // displayTitle flag is used to print the title for the fields
 const displayTitle = true
 
+// instanceTypeRegexp matches EC2 instance types of the form <family>.<size>, e.g. t2.micro or u-6tb1.metal.
+var instanceTypeRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]*\.[a-z0-9]+$`)
+
 var flagNamesToStackParameterKeys map[string]string
 
 func init() {
@@ -161,8 +165,8 @@ func createCluster(context *cli.Context, rdwr config.ReadWriter, ecsClient ecscl
 	}
 
 	// Check if cluster is specified
-	if ecsParams.Cluster == "" {
-		return fmt.Errorf("Please configure a cluster using the configure command or the '--%s' flag", command.ClusterFlag)
+	if err := ecsParams.ValidateClusterConfigured(); err != nil {
+		return err
 	}
 
 	// Check if cfn stack already exists
@@ -182,6 +186,9 @@ func createCluster(context *cli.Context, rdwr config.ReadWriter, ecsClient ecscl
 	if context.Bool(command.NoAutoAssignPublicIPAddressFlag) {
 		cfnParams.Add(cloudformation.ParameterKeyAssociatePublicIPAddress, "false")
 	}
+	if err := addConfigDefaults(cfnParams, ecsParams); err != nil {
+		return err
+	}
 
 	// Check if key pair exists
 	_, err = cfnParams.GetParameter(cloudformation.ParameterKeyKeyPairName)
@@ -278,7 +285,7 @@ func deleteCluster(context *cli.Context, rdwr config.ReadWriter, ecsClient ecscl
 
 	// Validate that cluster exists in ECS
 	ecsClient.Initialize(ecsParams)
-	if err := validateCluster(ecsParams.Cluster, ecsClient); err != nil {
+	if err := validateCluster(ecsParams, ecsClient); err != nil {
 		return err
 	}
 
@@ -328,7 +335,7 @@ func scaleCluster(context *cli.Context, rdwr config.ReadWriter, ecsClient ecscli
 
 	// Validate that cluster exists in ECS
 	ecsClient.Initialize(ecsParams)
-	if err := validateCluster(ecsParams.Cluster, ecsClient); err != nil {
+	if err := validateCluster(ecsParams, ecsClient); err != nil {
 		return err
 	}
 
@@ -360,7 +367,7 @@ func clusterPS(context *cli.Context, rdwr config.ReadWriter, ecsClient ecsclient
 
 	// Validate that cluster exists in ECS
 	ecsClient.Initialize(ecsParams)
-	if err := validateCluster(ecsParams.Cluster, ecsClient); err != nil {
+	if err := validateCluster(ecsParams, ecsClient); err != nil {
 		return nil, err
 	}
 	ec2Client := ec2client.NewEC2Client(ecsParams)
@@ -371,7 +378,12 @@ func clusterPS(context *cli.Context, rdwr config.ReadWriter, ecsClient ecsclient
 }
 
 // validateCluster validates if the cluster exists in ECS and is in "ACTIVE" state.
-func validateCluster(clusterName string, ecsClient ecsclient.ECSClient) error {
+func validateCluster(ecsParams *config.CliParams, ecsClient ecsclient.ECSClient) error {
+	if err := ecsParams.ValidateClusterConfigured(); err != nil {
+		return err
+	}
+
+	clusterName := ecsParams.Cluster
 	isClusterActive, err := ecsClient.IsActiveCluster(clusterName)
 	if err != nil {
 		return err
@@ -397,6 +409,21 @@ func deleteClusterPrompt(reader *bufio.Reader) error {
 	return nil
 }
 
+// addConfigDefaults adds the key pair and instance type from the ECS CLI config to the
+// cloudformation stack parameters, unless they were already specified with CLI flags.
+func addConfigDefaults(cfnParams *cloudformation.CfnStackParams, ecsParams *config.CliParams) error {
+	if _, err := cfnParams.GetParameter(cloudformation.ParameterKeyKeyPairName); err == cloudformation.ParameterNotFoundError && ecsParams.KeyPairName != "" {
+		cfnParams.Add(cloudformation.ParameterKeyKeyPairName, ecsParams.KeyPairName)
+	}
+	if _, err := cfnParams.GetParameter(cloudformation.ParameterKeyInstanceType); err == cloudformation.ParameterNotFoundError && ecsParams.InstanceType != "" {
+		if !instanceTypeRegexp.MatchString(ecsParams.InstanceType) {
+			return fmt.Errorf("Invalid instance type '%s' in the ECS CLI configuration. Expected a value such as 't2.micro'", ecsParams.InstanceType)
+		}
+		cfnParams.Add(cloudformation.ParameterKeyInstanceType, ecsParams.InstanceType)
+	}
+	return nil
+}
+
 // cliFlagsToCfnStackParams converts values set for CLI flags to cloudformation stack parameters.
 func cliFlagsToCfnStackParams(context *cli.Context) *cloudformation.CfnStackParams {
 	cfnParams := cloudformation.NewCfnStackParams()
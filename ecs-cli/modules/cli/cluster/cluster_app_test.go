@@ -40,11 +40,16 @@ const (
 )
 
 type mockReadWriter struct {
-	clusterName string
+	clusterName  string
+	keyPairName  string
+	instanceType string
 }
 
 func (rdwr *mockReadWriter) GetConfig() (*config.CliConfig, error) {
-	return config.NewCliConfig(rdwr.clusterName), nil
+	cliConfig := config.NewCliConfig(rdwr.clusterName)
+	cliConfig.KeyPairName = rdwr.keyPairName
+	cliConfig.InstanceType = rdwr.instanceType
+	return cliConfig, nil
 }
 
 func (rdwr *mockReadWriter) ReadFrom(ecsConfig *config.CliConfig) error {
@@ -242,6 +247,105 @@ func TestClusterUpWithoutKeyPair(t *testing.T) {
 	assert.Error(t, err, "Expected error for key pair name")
 }
 
+func TestClusterUpWithConfigDefaults(t *testing.T) {
+	defer os.Clearenv()
+	mockECS, mockCloudformation := setupTest(t)
+
+	gomock.InOrder(
+		mockECS.EXPECT().Initialize(gomock.Any()),
+		mockECS.EXPECT().CreateCluster(clusterName).Return(clusterName, nil),
+	)
+
+	gomock.InOrder(
+		mockCloudformation.EXPECT().Initialize(gomock.Any()),
+		mockCloudformation.EXPECT().ValidateStackExists(stackName).Return(errors.New("error")),
+		mockCloudformation.EXPECT().CreateStack(gomock.Any(), stackName, gomock.Any()).Do(func(x, y, z interface{}) {
+			cfnStackParams := z.(*cloudformation.CfnStackParams)
+			param, err := cfnStackParams.GetParameter(cloudformation.ParameterKeyKeyPairName)
+			assert.NoError(t, err, "Expected key pair param to be present")
+			assert.Equal(t, "config-key", aws.StringValue(param.ParameterValue), "Expected key pair from config")
+			param, err = cfnStackParams.GetParameter(cloudformation.ParameterKeyInstanceType)
+			assert.NoError(t, err, "Expected instance type param to be present")
+			assert.Equal(t, "t2.medium", aws.StringValue(param.ParameterValue), "Expected instance type from config")
+		}).Return("", nil),
+		mockCloudformation.EXPECT().WaitUntilCreateComplete(stackName).Return(nil),
+	)
+
+	flagSet := flag.NewFlagSet("ecs-cli-up", 0)
+	flagSet.Bool(command.CapabilityIAMFlag, true, "")
+
+	context := cli.NewContext(nil, flagSet, nil)
+	rdwr := &mockReadWriter{clusterName: clusterName, keyPairName: "config-key", instanceType: "t2.medium"}
+	err := createCluster(context, rdwr, mockECS, mockCloudformation, ami.NewStaticAmiIds())
+	assert.NoError(t, err, "Unexpected error bringing up cluster")
+}
+
+func TestClusterUpFlagsOverrideConfigDefaults(t *testing.T) {
+	defer os.Clearenv()
+	mockECS, mockCloudformation := setupTest(t)
+
+	gomock.InOrder(
+		mockECS.EXPECT().Initialize(gomock.Any()),
+		mockECS.EXPECT().CreateCluster(clusterName).Return(clusterName, nil),
+	)
+
+	gomock.InOrder(
+		mockCloudformation.EXPECT().Initialize(gomock.Any()),
+		mockCloudformation.EXPECT().ValidateStackExists(stackName).Return(errors.New("error")),
+		mockCloudformation.EXPECT().CreateStack(gomock.Any(), stackName, gomock.Any()).Do(func(x, y, z interface{}) {
+			cfnStackParams := z.(*cloudformation.CfnStackParams)
+			param, err := cfnStackParams.GetParameter(cloudformation.ParameterKeyKeyPairName)
+			assert.NoError(t, err, "Expected key pair param to be present")
+			assert.Equal(t, "flag-key", aws.StringValue(param.ParameterValue), "Expected key pair from flag")
+		}).Return("", nil),
+		mockCloudformation.EXPECT().WaitUntilCreateComplete(stackName).Return(nil),
+	)
+
+	flagSet := flag.NewFlagSet("ecs-cli-up", 0)
+	flagSet.Bool(command.CapabilityIAMFlag, true, "")
+	flagSet.String(command.KeypairNameFlag, "flag-key", "")
+
+	context := cli.NewContext(nil, flagSet, nil)
+	rdwr := &mockReadWriter{clusterName: clusterName, keyPairName: "config-key"}
+	err := createCluster(context, rdwr, mockECS, mockCloudformation, ami.NewStaticAmiIds())
+	assert.NoError(t, err, "Unexpected error bringing up cluster")
+}
+
+func TestClusterUpWithInvalidConfigInstanceType(t *testing.T) {
+	defer os.Clearenv()
+	mockECS, mockCloudformation := setupTest(t)
+
+	gomock.InOrder(
+		mockCloudformation.EXPECT().Initialize(gomock.Any()),
+		mockCloudformation.EXPECT().ValidateStackExists(stackName).Return(errors.New("error")),
+	)
+
+	flagSet := flag.NewFlagSet("ecs-cli-up", 0)
+	flagSet.Bool(command.CapabilityIAMFlag, true, "")
+	flagSet.String(command.KeypairNameFlag, "default", "")
+
+	context := cli.NewContext(nil, flagSet, nil)
+	rdwr := &mockReadWriter{clusterName: clusterName, instanceType: "large"}
+	err := createCluster(context, rdwr, mockECS, mockCloudformation, ami.NewStaticAmiIds())
+	assert.Error(t, err, "Expected error for invalid instance type")
+}
+
+func TestAddConfigDefaultsInstanceTypes(t *testing.T) {
+	for _, instanceType := range []string{"t2.micro", "m4.10xlarge", "u-6tb1.metal"} {
+		cfnParams := cloudformation.NewCfnStackParams()
+		err := addConfigDefaults(cfnParams, &config.CliParams{InstanceType: instanceType})
+		assert.NoError(t, err, "Unexpected error for instance type %s", instanceType)
+		param, err := cfnParams.GetParameter(cloudformation.ParameterKeyInstanceType)
+		assert.NoError(t, err, "Expected instance type param to be present")
+		assert.Equal(t, instanceType, aws.StringValue(param.ParameterValue), "Expected instance type from config")
+	}
+
+	for _, instanceType := range []string{"large", "T2.micro", "-t2.micro", "t2.micro."} {
+		err := addConfigDefaults(cloudformation.NewCfnStackParams(), &config.CliParams{InstanceType: instanceType})
+		assert.Error(t, err, "Expected error for instance type %s", instanceType)
+	}
+}
+
 func TestClusterUpWithSecurityGroupWithoutVPC(t *testing.T) {
 	defer os.Clearenv()
 	mockECS, mockCloudformation := setupTest(t)
@@ -566,6 +670,25 @@ func TestClusterScaleWithoutSize(t *testing.T) {
 	assert.Error(t, err, "Expected error scaling cluster when size is not specified")
 }
 
+func TestClusterScaleWithClusterNameEmpty(t *testing.T) {
+	newCliParams = func(context *cli.Context, rdwr config.ReadWriter) (*config.CliParams, error) {
+		return &config.CliParams{}, nil
+	}
+	defer os.Clearenv()
+	mockECS, mockCloudformation := setupTest(t)
+
+	// The empty cluster name must not reach ECS, which would resolve it to 'default'
+	mockECS.EXPECT().Initialize(gomock.Any())
+
+	flagSet := flag.NewFlagSet("ecs-cli-scale", 0)
+	flagSet.Bool(command.CapabilityIAMFlag, true, "")
+	flagSet.String(command.AsgMaxSizeFlag, "1", "")
+
+	context := cli.NewContext(nil, flagSet, nil)
+	err := scaleCluster(context, &mockReadWriter{clusterName: ""}, mockECS, mockCloudformation)
+	assert.Error(t, err, "Expected error scaling cluster when cluster is not configured")
+}
+
 func TestClusterPSTaskGetInfoFail(t *testing.T) {
 	testSession, err := session.NewSession()
 	assert.NoError(t, err, "Unexpected error in creating session")
@@ -76,6 +76,9 @@ func (projectFactory projectFactory) populateContext(ecsContext *context.Context
 		utils.LogError(err, "Unable to create an instance of ECSParams given the cli context")
 		return err
 	}
+	if err := params.ValidateClusterConfigured(); err != nil {
+		return err
+	}
 	ecsContext.ECSParams = params
 
 	// populate libcompose context
@@ -47,6 +47,7 @@ func TestPopulateContext(t *testing.T) {
 	defer os.Remove(tempDirName)
 	os.Setenv("HOME", tempDirName)
 
+	os.Setenv("ECS_CLUSTER", "cluster")
 	os.Setenv("AWS_REGION", "us-east-1")
 	os.Setenv("AWS_ACCESS_KEY", "AKIDEXAMPLE")
 	os.Setenv("AWS_SECRET_KEY", "secret")
@@ -64,6 +65,33 @@ func TestPopulateContext(t *testing.T) {
 	}
 }
 
+func TestPopulateContextWithoutCluster(t *testing.T) {
+	globalSet := flag.NewFlagSet("ecs-cli", 0)
+	globalContext := cli.NewContext(nil, globalSet, nil)
+	flagSet := flag.NewFlagSet("ecs-cli-up", 0)
+	cliContext := cli.NewContext(nil, flagSet, globalContext)
+	ecsContext := &context.Context{}
+
+	// Create a temprorary directory for the dummy ecs config
+	tempDirName, err := ioutil.TempDir("", "test")
+	if err != nil {
+		t.Fatal("Error while creating the dummy ecs config directory")
+	}
+	defer os.Remove(tempDirName)
+	os.Setenv("HOME", tempDirName)
+
+	// NOTE: no cluster set
+	os.Setenv("AWS_REGION", "us-east-1")
+	os.Setenv("AWS_ACCESS_KEY", "AKIDEXAMPLE")
+	os.Setenv("AWS_SECRET_KEY", "secret")
+	defer os.Clearenv()
+
+	projectFactory := projectFactory{}
+	err = projectFactory.populateContext(ecsContext, cliContext)
+	assert.Error(t, err, "Expected error populating the context without a cluster")
+	assert.Nil(t, ecsContext.ECSParams, "Expected ECS Params not to be set without a cluster")
+}
+
 func TestPopulateContextWithGlobalFlagOverrides(t *testing.T) {
 	// populate when compose file and project name flag overrides are provided
 	overrides := flag.NewFlagSet("ecs-cli", 0)
@@ -85,11 +113,13 @@ func TestPopulateContextWithGlobalFlagOverrides(t *testing.T) {
 	}
 	defer os.Remove(tempDirName)
 	os.Setenv("HOME", tempDirName)
+	os.Setenv("ECS_CLUSTER", "cluster")
 	os.Setenv("AWS_REGION", "us-east-1")
 	os.Setenv("AWS_ACCESS_KEY", "AKIDEXAMPLE")
 	os.Setenv("AWS_SECRET_KEY", "secret")
 
 	defer func() {
+		os.Unsetenv("ECS_CLUSTER")
 		os.Unsetenv("AWS_REGION")
 		os.Unsetenv("AWS_ACCESS_KEY")
 		os.Unsetenv("AWS_SECRET_KEY")
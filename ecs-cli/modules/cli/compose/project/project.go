@@ -14,18 +14,29 @@
 package project
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/Sirupsen/logrus"
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/cli/compose/context"
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/cli/compose/entity"
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/cli/compose/entity/service"
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/cli/compose/entity/task"
+	stsclient "github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients/aws/sts"
+	ecsconfig "github.com/aws/amazon-ecs-cli/ecs-cli/modules/config"
 
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/utils/compose"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/docker/libcompose/config"
 	"github.com/docker/libcompose/project"
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/commands"
 )
 
+const (
+	// arnPrefix is the prefix of values that are already ARNs, rather than role names.
+	arnPrefix = "arn:"
+)
+
 // Project is the starting point for the compose app to interact with and issue commands
 // It acts as a blanket for the context and entities created as a part of this compose project
 type Project interface {
@@ -136,6 +147,12 @@ func (p *ecsProject) transformTaskDefinition() error {
 	logrus.Debug("Transforming yaml to task definition...")
 	taskDefinitionName := utils.GetTaskDefinitionName(context.ECSParams.ComposeProjectNamePrefix, context.Context.ProjectName)
 	taskRoleArn := context.CLIContext.GlobalString(command.TaskRoleArnFlag)
+	if taskRoleArn != "" {
+		var err error
+		if taskRoleArn, err = expandRoleArn(taskRoleArn, context.ECSParams); err != nil {
+			return err
+		}
+	}
 	taskDefinition, err := utils.ConvertToTaskDefinition(taskDefinitionName, &context.Context, p.ServiceConfigs(), taskRoleArn)
 	if err != nil {
 		return err
@@ -144,6 +161,28 @@ func (p *ecsProject) transformTaskDefinition() error {
 	return nil
 }
 
+// newSTSClient creates the STS client used to look up the account of the caller.
+var newSTSClient = func(params *ecsconfig.CliParams) stsclient.Client {
+	return stsclient.NewClient(params)
+}
+
+// expandRoleArn expands an IAM role name to the role ARN in the configured AWS account, or in the
+// account of the caller if none is configured. Values that are already ARNs are returned unchanged.
+func expandRoleArn(roleName string, params *ecsconfig.CliParams) (string, error) {
+	if strings.HasPrefix(roleName, arnPrefix) {
+		return roleName, nil
+	}
+	accountID := params.AwsAccountID
+	if accountID == "" {
+		var err error
+		if accountID, err = newSTSClient(params).GetAWSAccountID(); err != nil {
+			return "", fmt.Errorf("Unable to expand the role name '%s' to an ARN: %s", roleName, err.Error())
+		}
+	}
+	partition := ecsconfig.PartitionForRegion(aws.StringValue(params.Session.Config.Region))
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", partition, accountID, roleName), nil
+}
+
 //* ----------------- commands ----------------- */
 
 func (p *ecsProject) Create() error {
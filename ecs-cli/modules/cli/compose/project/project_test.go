@@ -14,6 +14,7 @@
 package project
 
 import (
+	"errors"
 	"flag"
 	"io/ioutil"
 	"os"
@@ -22,10 +23,18 @@ import (
 	"testing"
 
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/cli/compose/context"
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/cli/compose/entity/mock"
+	stsclient "github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients/aws/sts"
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients/aws/sts/mock"
 	command "github.com/aws/amazon-ecs-cli/ecs-cli/modules/commands"
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/config"
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/utils/compose"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/docker/libcompose/project"
 	"github.com/docker/libcompose/yaml"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli"
 )
@@ -324,3 +333,100 @@ func setupTestProject(t *testing.T) *ecsProject {
 		Project: *libcomposeProject,
 	}
 }
+
+func TestTransformTaskDefinitionExpandsTaskRoleName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockSTS := mock_sts.NewMockClient(ctrl)
+	mockSTS.EXPECT().GetAWSAccountID().Return("123456789012", nil)
+	defer stubSTSClient(mockSTS)()
+
+	project := setupTaskRoleTestProject(t, ctrl, "my-app-role", &config.CliParams{
+		Session: session.New(&aws.Config{Region: aws.String("cn-north-1")}),
+	})
+	project.entity.(*mock_entity.MockProjectEntity).EXPECT().SetTaskDefinition(gomock.Any()).Do(func(taskDefinition *ecs.TaskDefinition) {
+		assert.Equal(t, "arn:aws-cn:iam::123456789012:role/my-app-role", aws.StringValue(taskDefinition.TaskRoleArn), "Expected role name to be expanded")
+	})
+
+	err := project.transformTaskDefinition()
+	assert.NoError(t, err, "Unexpected error transforming task definition")
+}
+
+func TestTransformTaskDefinitionExpandsTaskRoleNameWithConfiguredAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// no calls are expected on the STS client
+	defer stubSTSClient(mock_sts.NewMockClient(ctrl))()
+
+	project := setupTaskRoleTestProject(t, ctrl, "my-app-role", &config.CliParams{
+		Session:      session.New(&aws.Config{Region: aws.String("us-west-2")}),
+		AwsAccountID: "210987654321",
+	})
+	project.entity.(*mock_entity.MockProjectEntity).EXPECT().SetTaskDefinition(gomock.Any()).Do(func(taskDefinition *ecs.TaskDefinition) {
+		assert.Equal(t, "arn:aws:iam::210987654321:role/my-app-role", aws.StringValue(taskDefinition.TaskRoleArn), "Expected role name to be expanded")
+	})
+
+	err := project.transformTaskDefinition()
+	assert.NoError(t, err, "Unexpected error transforming task definition")
+}
+
+func TestTransformTaskDefinitionWithTaskRoleArn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer stubSTSClient(mock_sts.NewMockClient(ctrl))()
+
+	arn := "arn:aws:iam::123456789012:role/my-app-role"
+	project := setupTaskRoleTestProject(t, ctrl, arn, &config.CliParams{
+		Session: session.New(&aws.Config{Region: aws.String("us-west-2")}),
+	})
+	project.entity.(*mock_entity.MockProjectEntity).EXPECT().SetTaskDefinition(gomock.Any()).Do(func(taskDefinition *ecs.TaskDefinition) {
+		assert.Equal(t, arn, aws.StringValue(taskDefinition.TaskRoleArn), "Expected role ARN to be unchanged")
+	})
+
+	err := project.transformTaskDefinition()
+	assert.NoError(t, err, "Unexpected error transforming task definition")
+}
+
+func TestTransformTaskDefinitionWhenSTSFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockSTS := mock_sts.NewMockClient(ctrl)
+	mockSTS.EXPECT().GetAWSAccountID().Return("", errors.New("something failed"))
+	defer stubSTSClient(mockSTS)()
+
+	project := setupTaskRoleTestProject(t, ctrl, "my-app-role", &config.CliParams{
+		Session: session.New(&aws.Config{Region: aws.String("us-west-2")}),
+	})
+
+	err := project.transformTaskDefinition()
+	assert.Error(t, err, "Expected error when the account of the caller cannot be determined")
+}
+
+// stubSTSClient replaces newSTSClient with one returning client, and returns a func restoring it.
+func stubSTSClient(client stsclient.Client) func() {
+	original := newSTSClient
+	newSTSClient = func(*config.CliParams) stsclient.Client {
+		return client
+	}
+	return func() {
+		newSTSClient = original
+	}
+}
+
+func setupTaskRoleTestProject(t *testing.T, ctrl *gomock.Controller, taskRoleArn string, params *config.CliParams) *ecsProject {
+	project := setupTestProject(t)
+
+	globalSet := flag.NewFlagSet("ecs-cli", 0)
+	globalSet.String(command.ProjectNameFlag, testProjectName, "")
+	globalSet.String(command.TaskRoleArnFlag, taskRoleArn, "")
+	project.context.CLIContext = cli.NewContext(nil, nil, cli.NewContext(nil, globalSet, nil))
+	project.context.ECSParams = params
+	project.context.ComposeBytes = [][]byte{[]byte(`web:
+  image: web`)}
+	if err := project.parseCompose(); err != nil {
+		t.Fatal("Unexpected error parsing the compose string", err)
+	}
+	project.entity = mock_entity.NewMockProjectEntity(ctrl)
+
+	return project
+}
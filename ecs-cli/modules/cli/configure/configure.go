@@ -14,6 +14,7 @@
 package configure
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/Sirupsen/logrus"
@@ -22,22 +23,57 @@ import (
 	"github.com/urfave/cli"
 )
 
+// fieldError is an error caused by the value of a specific configure flag.
+type fieldError struct {
+	field   string
+	message string
+}
+
+func (e *fieldError) Error() string {
+	return e.message
+}
+
+// jsonError is the shape of the errors written with the --json-errors flag.
+type jsonError struct {
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+}
+
 // Configure is the callback for ConfigureCommand.
-func Configure(context *cli.Context) {
+func Configure(context *cli.Context) error {
 	ecsConfig, err := createECSConfigFromCli(context)
 	if err != nil {
-		logrus.Error("Error initializing: ", err)
-		return
+		return handleError(context, err)
 	}
 	rdwr, err := config.NewReadWriter()
 	if err != nil {
-		logrus.Error("Error initializing: ", err)
-		return
+		return handleError(context, err)
 	}
 	err = saveConfig(ecsConfig, rdwr, rdwr.Destination)
 	if err != nil {
+		return handleError(context, err)
+	}
+	return nil
+}
+
+// handleError logs the error, or returns it as a JSON formatted exit error when the
+// --json-errors flag is set so that automation can parse the failure.
+func handleError(context *cli.Context, err error) error {
+	if !context.Bool(command.JSONErrorsFlag) {
 		logrus.Error("Error initializing: ", err)
+		return nil
 	}
+	return cli.NewExitError(formatJSONError(err), 1)
+}
+
+func formatJSONError(err error) string {
+	output := jsonError{Error: err.Error()}
+	if fieldErr, ok := err.(*fieldError); ok {
+		output.Field = fieldErr.field
+	}
+	// Marshalling a struct of strings can not fail.
+	b, _ := json.Marshal(output)
+	return string(b)
 }
 
 // createECSConfigFromCli creates a new CliConfig object from the CLI context.
@@ -49,15 +85,18 @@ func createECSConfigFromCli(context *cli.Context) (*config.CliConfig, error) {
 	profile := context.String(command.ProfileFlag)
 	cluster := context.String(command.ClusterFlag)
 
-	if cluster == "" {
-		return nil, fmt.Errorf("Missing required argument '%s'", command.ClusterFlag)
+	// A cluster is only required when there is nothing else to configure; a
+	// region or profile on its own is enough for commands that do not target
+	// a cluster, such as the image commands.
+	if cluster == "" && region == "" && profile == "" {
+		return nil, &fieldError{field: command.ClusterFlag, message: fmt.Sprintf("Missing required argument: one of '%s', '%s' or '%s' is required", command.ClusterFlag, command.RegionFlag, command.ProfileFlag)}
 	}
 
 	// ONLY allow for profile OR access keys to be specified
 	isProfileSpecified := profile != ""
 	isAccessKeySpecified := accessKey != "" || secretKey != ""
 	if isProfileSpecified && isAccessKeySpecified {
-		return nil, fmt.Errorf("Both AWS Access/Secret Keys and Profile were provided; only one of the two can be specified")
+		return nil, &fieldError{field: command.ProfileFlag, message: "Both AWS Access/Secret Keys and Profile were provided; only one of the two can be specified"}
 	}
 
 	ecsConfig := config.NewCliConfig(cluster)
@@ -84,6 +123,10 @@ func saveConfig(ecsConfig *config.CliConfig, rdwr config.ReadWriter, dest *confi
 	if err != nil {
 		return err
 	}
+	if ecsConfig.Cluster == "" {
+		logrus.Info("Saved ECS CLI configuration without changing the cluster")
+		return nil
+	}
 	logrus.Infof("Saved ECS CLI configuration for cluster (%s)", ecsConfig.Cluster)
 	return nil
 }
@@ -14,10 +14,18 @@
 package configure
 
 import (
+	"bytes"
+	"errors"
 	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/Sirupsen/logrus"
 	command "github.com/aws/amazon-ecs-cli/ecs-cli/modules/commands"
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli"
 )
@@ -80,13 +88,64 @@ func TestConfigInitWithProfile(t *testing.T) {
 }
 
 func TestConfigInitWithoutCluster(t *testing.T) {
-	// Config init with no cluster should fail.
+	// Config init with no cluster should succeed when a profile and region are specified.
 	setProfileNoCluster := flag.NewFlagSet("ecs-cli", 0)
 	setProfileNoCluster.String(command.ProfileFlag, profileName, "")
 	setProfileNoCluster.String(command.RegionFlag, region, "")
 	context := cli.NewContext(nil, setProfileNoCluster, nil)
+	cfg, err := createECSConfigFromCli(context)
+	assert.NoError(t, err, "Unexpected error when cluster is not specified")
+	assert.Empty(t, cfg.Cluster, "Expected cluster name to be empty")
+	assert.Equal(t, region, cfg.Region, "Expected region to match")
+	assert.Equal(t, profileName, cfg.AwsProfile, "Expected AWS profile to match")
+}
+
+func TestConfigInitWithOnlyProfile(t *testing.T) {
+	// Config init with only a profile should succeed; the region comes from the profile.
+	setProfile := flag.NewFlagSet("ecs-cli", 0)
+	setProfile.String(command.ProfileFlag, profileName, "")
+	context := cli.NewContext(nil, setProfile, nil)
+	cfg, err := createECSConfigFromCli(context)
+	assert.NoError(t, err, "Unexpected error when only profile is specified")
+	assert.Empty(t, cfg.Cluster, "Expected cluster name to be empty")
+	assert.Equal(t, profileName, cfg.AwsProfile, "Expected AWS profile to match")
+}
+
+func TestConfigInitWithoutClusterRegionOrProfile(t *testing.T) {
+	// Config init with just access keys should fail.
+	setKeysOnly := flag.NewFlagSet("ecs-cli", 0)
+	setKeysOnly.String(command.SecretKeyFlag, awsSecretKey, "")
+	setKeysOnly.String(command.AccessKeyFlag, awsAccessKey, "")
+	context := cli.NewContext(nil, setKeysOnly, nil)
 	_, err := createECSConfigFromCli(context)
-	assert.Error(t, err, "Expected error when cluster is not specified")
+	assert.Error(t, err, "Expected error when cluster, region and profile are not specified")
+}
+
+func TestConfigureRegionKeepsSavedCluster(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "test")
+	assert.NoError(t, err, "Error creating a temporary directory")
+	defer os.RemoveAll(tempDirName)
+
+	os.Setenv(command.ConfigHomeEnvVar, tempDirName)
+	defer os.Unsetenv(command.ConfigHomeEnvVar)
+
+	// configure --cluster prod --region us-west-2
+	setClusterAndRegion := flag.NewFlagSet("ecs-cli", 0)
+	setClusterAndRegion.String(command.ClusterFlag, "prod", "")
+	setClusterAndRegion.String(command.RegionFlag, "us-west-2", "")
+	configureFromFlags(t, setClusterAndRegion)
+
+	// configure --region us-east-1
+	setRegion := flag.NewFlagSet("ecs-cli", 0)
+	setRegion.String(command.RegionFlag, "us-east-1", "")
+	configureFromFlags(t, setRegion)
+
+	rdwr, err := config.NewReadWriter()
+	assert.NoError(t, err, "Error creating config read writer")
+	cfg, err := rdwr.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Equal(t, "prod", cfg.Cluster, "Expected the saved cluster to be kept")
+	assert.Equal(t, "us-east-1", cfg.Region, "Expected region to be updated")
 }
 
 func TestConfigInitWithProfileAndKeys(t *testing.T) {
@@ -137,3 +196,104 @@ func TestConfigInitWithoutPrefixes(t *testing.T) {
 	assert.Empty(t, cfg.ComposeServiceNamePrefix, "Expected ComposeServiceNamePrefix to be empty")
 	assert.Empty(t, cfg.CFNStackNamePrefix, "Expected CFNStackNamePrefix to be empty")
 }
+
+func TestHandleErrorWithJSONErrors(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "test")
+	assert.NoError(t, err, "Error creating a temporary directory")
+	defer os.RemoveAll(tempDirName)
+
+	os.Setenv(command.ConfigHomeEnvVar, filepath.Join(tempDirName, "home"))
+	defer os.Unsetenv(command.ConfigHomeEnvVar)
+	rdwr, err := config.NewReadWriter()
+	assert.NoError(t, err, "Error creating config read writer")
+
+	// A directory in place of the config file makes the save fail
+	mode := os.FileMode(0700)
+	dest := &config.Destination{Path: filepath.Join(tempDirName, "bad"), Mode: &mode}
+	err = os.MkdirAll(filepath.Join(dest.Path, "config"), mode)
+	assert.NoError(t, err, "Error creating the config file directory")
+
+	var logOutput bytes.Buffer
+	logrus.SetOutput(&logOutput)
+	defer logrus.SetOutput(os.Stderr)
+
+	setJSONErrors := flag.NewFlagSet("ecs-cli", 0)
+	setJSONErrors.Bool(command.JSONErrorsFlag, true, "")
+	context := cli.NewContext(nil, setJSONErrors, nil)
+
+	err = saveConfig(config.NewCliConfig(clusterName), rdwr, dest)
+	assert.Error(t, err, "Expected error saving the config")
+
+	err = handleError(context, err)
+	exitErr, ok := err.(*cli.ExitError)
+	assert.True(t, ok, "Expected an exit error")
+	assert.Equal(t, 1, exitErr.ExitCode(), "Expected a non-zero exit code")
+	assert.True(t, strings.HasPrefix(exitErr.Error(), `{"error":"Unable to replace `), "Expected JSON error, got %s", exitErr.Error())
+	assert.Empty(t, logOutput.String(), "Expected nothing to be logged before the JSON error")
+}
+
+func TestConfigureWithJSONErrorsLogsNothing(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "test")
+	assert.NoError(t, err, "Error creating a temporary directory")
+	defer os.RemoveAll(tempDirName)
+
+	// A directory in place of the config file makes the save fail
+	err = os.MkdirAll(filepath.Join(tempDirName, "home", "config"), 0700)
+	assert.NoError(t, err, "Error creating the config file directory")
+
+	testCases := map[string]string{
+		"read writer fails": filepath.Join(tempDirName, "missing", "home"),
+		"save fails":        filepath.Join(tempDirName, "home"),
+	}
+	for name, configHome := range testCases {
+		os.Setenv(command.ConfigHomeEnvVar, configHome)
+
+		var logOutput bytes.Buffer
+		logrus.SetOutput(&logOutput)
+
+		setJSONErrors := flag.NewFlagSet("ecs-cli", 0)
+		setJSONErrors.Bool(command.JSONErrorsFlag, true, "")
+		setJSONErrors.String(command.ClusterFlag, clusterName, "")
+		err = Configure(cli.NewContext(nil, setJSONErrors, nil))
+
+		logrus.SetOutput(os.Stderr)
+		os.Unsetenv(command.ConfigHomeEnvVar)
+
+		_, ok := err.(*cli.ExitError)
+		assert.True(t, ok, "Expected an exit error when the %s", name)
+		assert.True(t, strings.HasPrefix(err.Error(), `{"error":`), "Expected JSON error when the %s, got %s", name, err.Error())
+		assert.Empty(t, logOutput.String(), "Expected nothing to be logged before the JSON error when the %s", name)
+	}
+}
+
+func TestHandleErrorWithJSONErrorsForField(t *testing.T) {
+	setJSONErrors := flag.NewFlagSet("ecs-cli", 0)
+	setJSONErrors.Bool(command.JSONErrorsFlag, true, "")
+	setJSONErrors.String(command.SecretKeyFlag, awsSecretKey, "")
+	setJSONErrors.String(command.AccessKeyFlag, awsAccessKey, "")
+	context := cli.NewContext(nil, setJSONErrors, nil)
+
+	_, err := createECSConfigFromCli(context)
+	assert.Error(t, err, "Expected error when cluster, region and profile are not specified")
+
+	err = handleError(context, err)
+	assert.Equal(t, `{"error":"Missing required argument: one of 'cluster', 'region' or 'profile' is required","field":"cluster"}`, err.Error(), "Expected JSON error with field")
+}
+
+// configureFromFlags saves the config from the given configure flags.
+func configureFromFlags(t *testing.T, flagSet *flag.FlagSet) {
+	cfg, err := createECSConfigFromCli(cli.NewContext(nil, flagSet, nil))
+	assert.NoError(t, err, "Unexpected error creating config from flags")
+	rdwr, err := config.NewReadWriter()
+	assert.NoError(t, err, "Error creating config read writer")
+	err = saveConfig(cfg, rdwr, rdwr.Destination)
+	assert.NoError(t, err, "Error saving config")
+}
+
+func TestHandleErrorWithoutJSONErrors(t *testing.T) {
+	context := cli.NewContext(nil, flag.NewFlagSet("ecs-cli", 0), nil)
+
+	// Errors are only logged, to keep the exit status unchanged
+	err := handleError(context, errors.New("Unable to write config"))
+	assert.NoError(t, err, "Expected error to be logged instead of returned")
+}
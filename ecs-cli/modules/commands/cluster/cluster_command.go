@@ -34,6 +34,7 @@ func DownCommand() cli.Command {
 	return cli.Command{
 		Name:   "down",
 		Usage:  "Deletes the CloudFormation stack that was created by ecs-cli up and the associated resources. The --force option is required.",
+		Before: ecscli.BeforeApp,
 		Action: cluster.ClusterDown,
 		Flags:  append(clusterDownFlags(), command.OptionalClusterFlag(), command.OptionalRegionFlag()),
 	}
@@ -43,6 +44,7 @@ func ScaleCommand() cli.Command {
 	return cli.Command{
 		Name:   "scale",
 		Usage:  "Modifies the number of container instances in your cluster. This command changes the desired and maximum instance count in the Auto Scaling group created by the ecs-cli up command. You can use this command to scale up (increase the number of instances) or scale down (decrease the number of instances) your cluster.",
+		Before: ecscli.BeforeApp,
 		Action: cluster.ClusterScale,
 		Flags:  append(clusterScaleFlags(), command.OptionalClusterFlag(), command.OptionalRegionFlag()),
 	}
@@ -52,6 +54,7 @@ func PsCommand() cli.Command {
 	return cli.Command{
 		Name:   "ps",
 		Usage:  "Lists all of the running containers in your ECS cluster",
+		Before: ecscli.BeforeApp,
 		Action: cluster.ClusterPS,
 		Flags:  []cli.Flag{command.OptionalClusterFlag(), command.OptionalRegionFlag()},
 	}
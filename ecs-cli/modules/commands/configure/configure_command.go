@@ -16,6 +16,7 @@ package configureCommand
 import (
 	"fmt"
 
+	ecscli "github.com/aws/amazon-ecs-cli/ecs-cli/modules"
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/cli/configure"
 	flags "github.com/aws/amazon-ecs-cli/ecs-cli/modules/commands"
 	"github.com/urfave/cli"
@@ -25,7 +26,8 @@ import (
 func ConfigureCommand() cli.Command {
 	return cli.Command{
 		Name:   "configure",
-		Usage:  "Configures your AWS credentials, the AWS region to use, and the ECS cluster name to use with the Amazon ECS CLI. The resulting configuration is stored in the ~/.ecs/config file.",
+		Usage:  "Configures your AWS credentials, the AWS region to use, and the ECS cluster name to use with the Amazon ECS CLI. The resulting configuration is stored in the ~/.ecs/config file, or in $ECS_CONFIG_HOME/config when ECS_CONFIG_HOME is set.",
+		Before: ecscli.BeforeApp,
 		Action: configure.Configure,
 		Flags:  configureFlags(),
 	}
@@ -90,5 +92,11 @@ func configureFlags() []cli.Flag {
 				"[Optional] Specifies the prefix added to the AWS CloudFormation stack created on ecs-cli up. Format <prefix><cluster-name>.",
 			),
 		},
+		cli.BoolFlag{
+			Name: flags.JSONErrorsFlag,
+			Usage: fmt.Sprintf(
+				"[Optional] Writes errors to stderr as JSON objects with 'error' and 'field' keys and exits with a non-zero status.",
+			),
+		},
 	}
 }
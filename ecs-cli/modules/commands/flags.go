@@ -30,7 +30,9 @@ const (
 	ProfileFlag            = "profile"
 	ClusterFlag            = "cluster"
 	ClusterEnvVar          = "ECS_CLUSTER"
+	ConfigHomeEnvVar       = "ECS_CONFIG_HOME"
 	VerboseFlag            = "verbose"
+	JSONErrorsFlag         = "json-errors"
 
 	ComposeProjectNamePrefixFlag         = "compose-project-name-prefix"
 	ComposeProjectNamePrefixDefaultValue = "ecscompose-"
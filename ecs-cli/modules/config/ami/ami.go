@@ -13,11 +13,17 @@
 
 package ami
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // ECSAmiIds interface is used to get the ami id for a specified region.
 type ECSAmiIds interface {
 	Get(string) (string, error)
+	Export(...string) (map[string]string, error)
+	SupportedRegions() []string
 }
 
 // staticAmiIds impmenets the ECSAmiIds interface to get the AMI id for
@@ -47,8 +53,36 @@ func NewStaticAmiIds() ECSAmiIds {
 func (c *staticAmiIds) Get(region string) (string, error) {
 	id, exists := c.regionToId[region]
 	if !exists {
-		return "", fmt.Errorf("Could not find ami id for region '%s'", region)
+		return "", fmt.Errorf("Could not find ami id for region '%s'. Supported regions are: %s", region, strings.Join(c.SupportedRegions(), ", "))
 	}
 
 	return id, nil
 }
+
+// Export returns the ami ids for the specified regions, keyed by region. If no regions are
+// specified, the ami ids for all the known regions are returned.
+func (c *staticAmiIds) Export(regions ...string) (map[string]string, error) {
+	if len(regions) == 0 {
+		regions = c.SupportedRegions()
+	}
+
+	regionToId := make(map[string]string, len(regions))
+	for _, region := range regions {
+		id, err := c.Get(region)
+		if err != nil {
+			return nil, err
+		}
+		regionToId[region] = id
+	}
+	return regionToId, nil
+}
+
+// SupportedRegions returns the sorted list of regions that have an ami id.
+func (c *staticAmiIds) SupportedRegions() []string {
+	regions := make([]string, 0, len(c.regionToId))
+	for region := range c.regionToId {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
@@ -0,0 +1,69 @@
+// Copyright 2015-2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//	http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ami
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportedRegions(t *testing.T) {
+	amiIds := NewStaticAmiIds()
+
+	regions := amiIds.SupportedRegions()
+	assert.True(t, sort.StringsAreSorted(regions), "Expected supported regions to be sorted")
+	assert.Contains(t, regions, "us-east-1", "Expected us-east-1 to be supported")
+	for _, region := range regions {
+		_, err := amiIds.Get(region)
+		assert.NoError(t, err, "Expected ami id for supported region %s", region)
+	}
+}
+
+func TestGetWithUnsupportedRegion(t *testing.T) {
+	amiIds := NewStaticAmiIds()
+
+	_, err := amiIds.Get("mars-north-1")
+	assert.Error(t, err, "Expected error for unsupported region")
+	assert.Contains(t, err.Error(), strings.Join(amiIds.SupportedRegions(), ", "), "Expected error to list the supported regions")
+}
+
+func TestExport(t *testing.T) {
+	amiIds := NewStaticAmiIds()
+
+	regionToId, err := amiIds.Export()
+	assert.NoError(t, err, "Unexpected error exporting ami ids")
+	id, err := amiIds.Get("us-west-2")
+	assert.NoError(t, err, "Unexpected error getting ami id")
+	assert.Equal(t, id, regionToId["us-west-2"], "Expected exported ami id to match")
+}
+
+func TestExportWithRegions(t *testing.T) {
+	amiIds := NewStaticAmiIds()
+
+	regionToId, err := amiIds.Export("us-east-1", "eu-west-1")
+	assert.NoError(t, err, "Unexpected error exporting ami ids")
+	assert.Len(t, regionToId, 2, "Expected only the requested regions")
+	assert.Equal(t, "ami-83af8395", regionToId["us-east-1"], "Expected ami id for us-east-1")
+	assert.Equal(t, "ami-5f140c39", regionToId["eu-west-1"], "Expected ami id for eu-west-1")
+}
+
+func TestExportWithUnknownRegion(t *testing.T) {
+	amiIds := NewStaticAmiIds()
+
+	_, err := amiIds.Export("us-east-1", "mars-north-1")
+	assert.Error(t, err, "Expected error exporting an unknown region")
+}
@@ -15,25 +15,43 @@ package config
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Sirupsen/logrus"
 	cli "github.com/aws/amazon-ecs-cli/ecs-cli/modules/commands"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
 )
 
 const (
 	ecsSectionKey               = "ecs"
+	endpointsSectionKey         = "endpoints"
+	regionAliasesSectionKey     = "region_aliases"
+	clusterKey                  = "cluster"
 	composeProjectNamePrefixKey = "compose-project-name-prefix"
 	composeServiceNamePrefixKey = "compose-service-name-prefix"
 	cfnStackNamePrefixKey       = "cfn-stack-name-prefix"
+	redactedValue               = "********"
 )
 
 // CliConfig is the top level struct used to map to the ini config.
 type CliConfig struct {
 	// TODO Add metadata information like version etc.
 	*SectionKeys `ini:"ecs"`
+	// Endpoints maps AWS service ids (e.g. ecs, ec2, cloudformation) to custom endpoint URLs.
+	// It is read from and written to the 'endpoints' section by the IniReadWriter.
+	Endpoints map[string]string `ini:"-"`
+	// RegionAliases maps friendly names (e.g. primary, dr) to AWS regions.
+	// It is read from and written to the 'region_aliases' section by the IniReadWriter.
+	RegionAliases map[string]string `ini:"-"`
 }
 
 // SectionKeys is the struct embedded in CliConfig. It groups all the keys in the 'ecs' section in the ini file.
@@ -46,11 +64,39 @@ type SectionKeys struct {
 	ComposeProjectNamePrefix string `ini:"compose-project-name-prefix"`
 	ComposeServiceNamePrefix string `ini:"compose-service-name-prefix"`
 	CFNStackNamePrefix       string `ini:"cfn-stack-name-prefix"`
+	// AllowedRegions is an optional comma separated allowlist of regions that
+	// ecs-cli may operate in. It is not set by the configure command.
+	AllowedRegions []string `ini:"allowed_regions,omitempty"`
+	// AwsAccountID is an optional account id that the resolved credentials must
+	// belong to. It is verified with STS only when set.
+	AwsAccountID string `ini:"aws_account_id,omitempty"`
+	// KeyPairName and InstanceType are optional defaults used by 'ecs-cli up' when
+	// the corresponding flags are omitted.
+	KeyPairName  string `ini:"key_pair,omitempty"`
+	InstanceType string `ini:"instance_type,omitempty"`
+	// ConfigBackups is the optional number of timestamped backups of the config file
+	// to keep when it is overwritten. No backups are written when it is not set.
+	ConfigBackups string `ini:"config_backups,omitempty"`
+	// Color (auto, always or never) and Quiet are optional output preferences.
+	Color string `ini:"color,omitempty"`
+	Quiet bool   `ini:"quiet,omitempty"`
+	// APITimeout is an optional duration (e.g. 30s) that bounds each AWS API request.
+	APITimeout string `ini:"api_timeout,omitempty"`
+	// RegionFromName is an optional regular expression whose first capture group extracts
+	// a region, or a region alias, from the cluster name when no region is set.
+	RegionFromName string `ini:"region_from_name,omitempty"`
 }
 
+// Valid values for the color preference.
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
 // NewCliConfig creates a new instance of CliConfig from the cluster name.
 func NewCliConfig(cluster string) *CliConfig {
-	return &CliConfig{&SectionKeys{Cluster: cluster}}
+	return &CliConfig{SectionKeys: &SectionKeys{Cluster: cluster}}
 }
 
 // ToAWSSession creates a new Session object from the CliConfig object.
@@ -60,6 +106,7 @@ func NewCliConfig(cluster string) *CliConfig {
 //    a) AWS_REGION (OR)
 //    b) AWS_DEFAULT_REGION
 //  2) ECS Config - attempts to fetch the region from the ECS config file
+//    a) region, or the region_from_name pattern applied to the cluster name
 //  3) AWS Profile - attempts to use region from AWS profile name
 //    a) profile name from ECS config file (OR)
 //    b) AWS_PROFILE environment variable (OR)
@@ -81,28 +128,70 @@ func (cfg *CliConfig) ToAWSSession() (*session.Session, error) {
 }
 
 func (cfg *CliConfig) toAWSSessionWithConfig(svcConfig aws.Config) (*session.Session, error) {
+	region, err := cfg.getRegion()
+	if err != nil {
+		return nil, err
+	}
+	svcConfig.Region = aws.String(region)
+
+	svcSession, err := cfg.newSession(svcConfig)
+	if err != nil {
+		return nil, err
+	}
+	region = *svcSession.Config.Region
+	if region == "" {
+		return nil, fmt.Errorf("Set a region using ecs-cli configure command with the --%s flag or %s environment variable or --%s flag", cli.RegionFlag, cli.AwsRegionEnvVar, cli.ProfileFlag)
+	}
+
+	return svcSession, nil
+}
+
+// Credentials returns the credentials for the CliConfig, resolved in the same order as in
+// ToAWSSession. It lets callers build clients without reading the credential fields directly,
+// and does not require a region to be configured.
+func (cfg *CliConfig) Credentials() (*credentials.Credentials, error) {
+	return cfg.credentialsWithConfig(aws.Config{})
+}
+
+func (cfg *CliConfig) credentialsWithConfig(svcConfig aws.Config) (*credentials.Credentials, error) {
+	svcSession, err := cfg.newSession(svcConfig)
+	if err != nil {
+		return nil, err
+	}
+	return svcSession.Config.Credentials, nil
+}
+
+// newSession creates a session with the api timeout and custom endpoints from the config. It uses
+// the credentials from the environment or the ECS config if they are set, and otherwise leaves the
+// session to resolve them from the AWS profile (including assume role) or the EC2 instance role.
+func (cfg *CliConfig) newSession(svcConfig aws.Config) (*session.Session, error) {
 	credentialProviders := cfg.getInitialCredentialProviders()
 	chainCredentials := credentials.NewChainCredentials(credentialProviders)
 	if _, err := chainCredentials.Get(); err == nil {
 		svcConfig.Credentials = chainCredentials
 	}
 
-	svcConfig.Region = aws.String(cfg.getRegion())
-
-	svcSession, err := session.NewSessionWithOptions(session.Options{
-		Config:            svcConfig,
-		Profile:           cfg.AwsProfile,
-		SharedConfigState: session.SharedConfigEnable,
-	})
+	timeout, err := cfg.getAPITimeout()
 	if err != nil {
 		return nil, err
 	}
-	region := *svcSession.Config.Region
-	if region == "" {
-		return nil, fmt.Errorf("Set a region using ecs-cli configure command with the --%s flag or %s environment variable or --%s flag", cli.RegionFlag, cli.AwsRegionEnvVar, cli.ProfileFlag)
+	if timeout > 0 && svcConfig.HTTPClient == nil {
+		svcConfig.HTTPClient = &http.Client{Timeout: timeout}
 	}
 
-	return svcSession, nil
+	if len(cfg.Endpoints) > 0 && svcConfig.EndpointResolver == nil {
+		resolver, err := cfg.getEndpointResolver()
+		if err != nil {
+			return nil, err
+		}
+		svcConfig.EndpointResolver = resolver
+	}
+
+	return session.NewSessionWithOptions(session.Options{
+		Config:            svcConfig,
+		Profile:           cfg.AwsProfile,
+		SharedConfigState: session.SharedConfigEnable,
+	})
 }
 
 // getInitialCredentialProviders gets the starting chain of credential providers to use when creating service clients.
@@ -111,7 +200,7 @@ func (cfg *CliConfig) getInitialCredentialProviders() []credentials.Provider {
 	// Order of credential resolution
 	//  1) Environment Variable
 	//  2) ECS Config
-	// the rest are handled by session.NewSessionWithOptions invoked in ToAWSSession()
+	// the rest are handled by session.NewSessionWithOptions invoked in newSession()
 	credentialProviders := []credentials.Provider{
 		&credentials.EnvProvider{},
 		&credentials.StaticProvider{
@@ -125,11 +214,39 @@ func (cfg *CliConfig) getInitialCredentialProviders() []credentials.Provider {
 	return credentialProviders
 }
 
+// getEndpointResolver returns a resolver that uses the custom endpoints from the config and
+// falls back to the default AWS endpoints for any other service.
+func (cfg *CliConfig) getEndpointResolver() (endpoints.Resolver, error) {
+	if err := cfg.validateEndpoints(); err != nil {
+		return nil, err
+	}
+
+	defaultResolver := endpoints.DefaultResolver()
+	return endpoints.ResolverFunc(func(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+		if endpoint, ok := cfg.Endpoints[service]; ok {
+			return endpoints.ResolvedEndpoint{URL: endpoint, SigningRegion: region}, nil
+		}
+		return defaultResolver.EndpointFor(service, region, opts...)
+	}), nil
+}
+
+// validateEndpoints returns an error if a custom endpoint is not an absolute URL.
+func (cfg *CliConfig) validateEndpoints() error {
+	for service, endpoint := range cfg.Endpoints {
+		if u, err := url.Parse(endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("Invalid endpoint URL '%s' for service '%s'", endpoint, service)
+		}
+	}
+	return nil
+}
+
 // getRegion gets the region to use from environment variables or ecs-cli's config file..
-func (cfg *CliConfig) getRegion() string {
+// Region aliases from the config are resolved to the regions they map to.
+func (cfg *CliConfig) getRegion() (string, error) {
 	// Order of region resolution
 	//  1) Environment Variable
 	//  2) ECS Config
+	//  3) Cluster name, if a region_from_name pattern is configured
 	// the rest are handled by session.NewSessionWithOptions invoked in ToAWSSession()
 	region := ""
 	// Search the chain of environment variables for region.
@@ -142,5 +259,168 @@ func (cfg *CliConfig) getRegion() string {
 	if region == "" {
 		region = cfg.Region
 	}
-	return region
+	if region == "" && cfg.RegionFromName != "" {
+		regionFromName, err := cfg.getRegionFromClusterName()
+		if err != nil {
+			return "", err
+		}
+		region = regionFromName
+	}
+	if aliasedRegion, ok := cfg.RegionAliases[region]; ok {
+		if !isKnownRegion(aliasedRegion) {
+			return "", fmt.Errorf("Region alias '%s' maps to an invalid region '%s'", region, aliasedRegion)
+		}
+		region = aliasedRegion
+	}
+	return region, nil
+}
+
+// getRegionFromClusterName extracts the region from the cluster name using the region_from_name
+// pattern. It returns an empty region if the cluster name does not match.
+func (cfg *CliConfig) getRegionFromClusterName() (string, error) {
+	pattern, err := cfg.getRegionFromNamePattern()
+	if err != nil {
+		return "", err
+	}
+	matches := pattern.FindStringSubmatch(cfg.Cluster)
+	if len(matches) < 2 || matches[1] == "" {
+		return "", nil
+	}
+	region := matches[1]
+	if _, ok := cfg.RegionAliases[region]; !ok && !isKnownRegion(region) {
+		return "", fmt.Errorf("Cluster name '%s' maps to an invalid region '%s'", cfg.Cluster, region)
+	}
+	return region, nil
+}
+
+// getRegionFromNamePattern compiles the region_from_name pattern. It returns nil if it is not set.
+func (cfg *CliConfig) getRegionFromNamePattern() (*regexp.Regexp, error) {
+	if cfg.RegionFromName == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(cfg.RegionFromName)
+	if err != nil || pattern.NumSubexp() < 1 {
+		return nil, fmt.Errorf("Invalid region_from_name '%s' in the ECS CLI configuration. Expected a regular expression with a capture group", cfg.RegionFromName)
+	}
+	return pattern, nil
+}
+
+// knownPartitions are the AWS partitions known to the SDK.
+var knownPartitions = []endpoints.Partition{endpoints.AwsPartition(), endpoints.AwsCnPartition(), endpoints.AwsUsGovPartition()}
+
+// isKnownRegion returns true if the region belongs to one of the AWS partitions known to the SDK.
+func isKnownRegion(region string) bool {
+	for _, partition := range knownPartitions {
+		if _, ok := partition.Regions()[region]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// PartitionForRegion returns the id of the AWS partition (e.g. aws-cn) that the region belongs to,
+// or the standard 'aws' partition if the region is not known.
+func PartitionForRegion(region string) string {
+	for _, partition := range knownPartitions {
+		if _, ok := partition.Regions()[region]; ok {
+			return partition.ID()
+		}
+	}
+	return endpoints.AwsPartitionID
+}
+
+// getAPITimeout parses the api timeout from the config. It returns 0 if no timeout is set.
+func (cfg *CliConfig) getAPITimeout() (time.Duration, error) {
+	if cfg.APITimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(cfg.APITimeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("Invalid api_timeout '%s' in the ECS CLI configuration. Expected a positive duration such as '30s'", cfg.APITimeout)
+	}
+	return timeout, nil
+}
+
+// getConfigBackups parses the number of config backups to keep. It returns 0 if it is not set.
+func (cfg *CliConfig) getConfigBackups() (int, error) {
+	if cfg.ConfigBackups == "" {
+		return 0, nil
+	}
+	backups, err := strconv.Atoi(cfg.ConfigBackups)
+	if err != nil || backups < 0 {
+		return 0, fmt.Errorf("Invalid config_backups '%s' in the ECS CLI configuration. Expected a non-negative integer", cfg.ConfigBackups)
+	}
+	return backups, nil
+}
+
+// ApplyOutputPreferences configures logging to honor the color and quiet preferences.
+// Quiet suppresses informational output and warnings, leaving only errors.
+func (cfg *CliConfig) ApplyOutputPreferences() {
+	logrus.SetFormatter(&logrus.TextFormatter{
+		ForceColors:   cfg.Color == ColorAlways,
+		DisableColors: cfg.Color == ColorNever,
+	})
+	if cfg.Quiet {
+		logrus.SetLevel(logrus.ErrorLevel)
+	}
+}
+
+// validateColor returns an error if the color preference is set to an unknown value.
+func (cfg *CliConfig) validateColor() error {
+	switch cfg.Color {
+	case "", ColorAuto, ColorAlways, ColorNever:
+		return nil
+	}
+	return fmt.Errorf("Invalid color '%s' in the ECS CLI configuration. Valid values are: %s, %s, %s", cfg.Color, ColorAuto, ColorAlways, ColorNever)
+}
+
+// validateAllowedRegion returns an error if an allowlist of regions is configured
+// and the given region is not in it.
+func (cfg *CliConfig) validateAllowedRegion(region string) error {
+	if len(cfg.AllowedRegions) == 0 {
+		return nil
+	}
+	for _, allowedRegion := range cfg.AllowedRegions {
+		if region == allowedRegion {
+			return nil
+		}
+	}
+	return fmt.Errorf("Region '%s' is not allowed by the ECS CLI configuration. Allowed regions are: %s", region, strings.Join(cfg.AllowedRegions, ", "))
+}
+
+// ExportEnvironment returns the region and credentials resolved from the CliConfig as shell
+// export lines, suitable for eval "$(...)". If redact is set, the secret key and session token
+// are masked so that the output can be displayed instead.
+func (cfg *CliConfig) ExportEnvironment(redact bool) ([]string, error) {
+	svcSession, err := cfg.ToAWSSession()
+	if err != nil {
+		return nil, err
+	}
+	creds, err := svcSession.Config.Credentials.Get()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to resolve AWS credentials: %s", err.Error())
+	}
+
+	secretKey, sessionToken := creds.SecretAccessKey, creds.SessionToken
+	if redact {
+		secretKey = redactedValue
+		if sessionToken != "" {
+			sessionToken = redactedValue
+		}
+	}
+
+	exports := []string{
+		exportLine("AWS_ACCESS_KEY_ID", creds.AccessKeyID),
+		exportLine("AWS_SECRET_ACCESS_KEY", secretKey),
+	}
+	if sessionToken != "" {
+		exports = append(exports, exportLine("AWS_SESSION_TOKEN", sessionToken))
+	}
+	exports = append(exports, exportLine(cli.AwsRegionEnvVar, aws.StringValue(svcSession.Config.Region)))
+	return exports, nil
+}
+
+// exportLine returns a shell export statement with the value single quoted.
+func exportLine(name, value string) string {
+	return fmt.Sprintf("export %s='%s'", name, strings.Replace(value, "'", `'\''`, -1))
 }
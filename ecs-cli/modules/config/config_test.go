@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/stretchr/testify/assert"
@@ -183,8 +184,89 @@ func testRegionInSession(t *testing.T, inputConfig *CliConfig, expectedRegion st
 	assert.Equal(t, expectedRegion, aws.StringValue(awsConfig.Region), "Expected region to match")
 }
 
+func TestRegionWhenUsingRegionAlias(t *testing.T) {
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.Region = "primary"
+	ecsConfig.RegionAliases = map[string]string{"primary": "us-east-1", "dr": "us-west-2"}
+	os.Clearenv()
+
+	testRegionInSession(t, ecsConfig, "us-east-1")
+
+	// Literal regions are not affected by aliases
+	ecsConfig.Region = "eu-west-1"
+	testRegionInSession(t, ecsConfig, "eu-west-1")
+}
+
+func TestRegionWhenUsingRegionAliasWithInvalidRegion(t *testing.T) {
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.Region = "primary"
+	ecsConfig.RegionAliases = map[string]string{"primary": "us-nowhere-1"}
+	os.Clearenv()
+
+	_, err := ecsConfig.ToAWSSession()
+	assert.Error(t, err, "Expected error for alias pointing at an invalid region")
+}
+
+func TestRegionFromClusterName(t *testing.T) {
+	ecsConfig := NewCliConfig("app-use1")
+	ecsConfig.RegionFromName = `-(\w+)$`
+	ecsConfig.RegionAliases = map[string]string{"use1": "us-east-1"}
+	os.Clearenv()
+
+	testRegionInSession(t, ecsConfig, "us-east-1")
+
+	// A region in the config takes precedence over the cluster name
+	ecsConfig.Region = "eu-west-1"
+	testRegionInSession(t, ecsConfig, "eu-west-1")
+}
+
+func TestRegionFromClusterNameWithoutMatch(t *testing.T) {
+	ecsConfig := NewCliConfig("app")
+	ecsConfig.RegionFromName = `-(\w+)$`
+	ecsConfig.AwsProfile = customProfileName
+
+	os.Setenv("AWS_CONFIG_FILE", "aws_config_example.ini")
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "aws_credentials_example.ini")
+	defer os.Clearenv()
+
+	// Falls back to the region of the AWS profile
+	testRegionInSession(t, ecsConfig, customAwsRegion)
+}
+
+func TestRegionFromClusterNameWithInvalidRegion(t *testing.T) {
+	ecsConfig := NewCliConfig("app-mars1")
+	ecsConfig.RegionFromName = `-(\w+)$`
+	os.Clearenv()
+
+	_, err := ecsConfig.ToAWSSession()
+	assert.Error(t, err, "Expected error for a cluster name that maps to an invalid region")
+}
+
 //-------------------------------END OF REGION TESTS----------------------------
 
+func TestCustomEndpoints(t *testing.T) {
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.AwsAccessKey = awsAccessKey
+	ecsConfig.AwsSecretKey = awsSecretKey
+	ecsConfig.Region = region
+	ecsConfig.Endpoints = map[string]string{endpoints.EcsServiceID: "http://localhost:4566"}
+
+	awsSession, err := ecsConfig.ToAWSSession()
+	assert.NoError(t, err, "Unexpected error generating a new session")
+
+	assert.Equal(t, "http://localhost:4566", awsSession.ClientConfig(endpoints.EcsServiceID).Endpoint, "Expected custom ECS endpoint")
+	assert.Equal(t, "https://ec2.us-east-1.amazonaws.com", awsSession.ClientConfig(endpoints.Ec2ServiceID).Endpoint, "Expected default EC2 endpoint")
+}
+
+func TestCustomEndpointsWithInvalidURL(t *testing.T) {
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.Region = region
+	ecsConfig.Endpoints = map[string]string{endpoints.EcsServiceID: "localhost"}
+
+	_, err := ecsConfig.ToAWSSession()
+	assert.Error(t, err, "Expected error when endpoint is not a valid URL")
+}
+
 //------------------------------------------------------------------------------
 // ToAWSSession() --> CREDENTIALS TESTS
 // Order of resolution:
@@ -305,7 +387,20 @@ func TestCredentialsWhenUsingAssumeRoleProfile(t *testing.T) {
 	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "aws_credentials_example.ini")
 	defer os.Clearenv()
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	server := newAssumeRoleServer()
+	defer server.Close()
+
+	startingConfig := aws.Config{}
+	startingConfig.Endpoint = aws.String(server.URL)
+	startingConfig.DisableSSL = aws.Bool(true)
+
+	// invoke test and verify
+	testCredentialsInSessionWithConfig(t, ecsConfig, &startingConfig, assumeRoleAccessKey, assumeRoleSecretKey)
+}
+
+// newAssumeRoleServer returns a server that responds to STS AssumeRole requests.
+func newAssumeRoleServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		const respMsg = `
 	<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
 	  <AssumeRoleResult>
@@ -327,13 +422,6 @@ func TestCredentialsWhenUsingAssumeRoleProfile(t *testing.T) {
 	`
 		w.Write([]byte(fmt.Sprintf(respMsg, time.Now().Add(15*time.Minute).Format("2006-01-02T15:04:05Z"))))
 	}))
-
-	startingConfig := aws.Config{}
-	startingConfig.Endpoint = aws.String(server.URL)
-	startingConfig.DisableSSL = aws.Bool(true)
-
-	// invoke test and verify
-	testCredentialsInSessionWithConfig(t, ecsConfig, &startingConfig, assumeRoleAccessKey, assumeRoleSecretKey)
 }
 
 // 4) Use credentials from EC2 Instance Role
@@ -414,9 +502,146 @@ func testCredentialsInSessionWithConfig(t *testing.T, inputConfig *CliConfig, ec
 }
 
 func verifyCredentialsInSession(t *testing.T, awsSession *session.Session, expectedAccessKey, expectedSecretKey string) {
-	awsConfig := awsSession.Config
-	resolvedCredentials, err := awsConfig.Credentials.Get()
+	verifyCredentials(t, awsSession.Config.Credentials, expectedAccessKey, expectedSecretKey)
+}
+
+func verifyCredentials(t *testing.T, creds *credentials.Credentials, expectedAccessKey, expectedSecretKey string) {
+	resolvedCredentials, err := creds.Get()
 	assert.NoError(t, err, "Unexpected error fetching credentials from the chain provider")
 	assert.Equal(t, expectedAccessKey, resolvedCredentials.AccessKeyID, "Expected access key to match")
 	assert.Equal(t, expectedSecretKey, resolvedCredentials.SecretAccessKey, "Expected secret key to match")
 }
+
+func TestCredentialsFromECSConfig(t *testing.T) {
+	os.Clearenv()
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.Region = region
+	ecsConfig.AwsAccessKey = awsAccessKey
+	ecsConfig.AwsSecretKey = awsSecretKey
+
+	creds, err := ecsConfig.Credentials()
+	assert.NoError(t, err, "Unexpected error getting credentials")
+	verifyCredentials(t, creds, awsAccessKey, awsSecretKey)
+}
+
+func TestCredentialsWithoutRegion(t *testing.T) {
+	os.Clearenv()
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.AwsAccessKey = awsAccessKey
+	ecsConfig.AwsSecretKey = awsSecretKey
+
+	// NOTE: no region set
+	creds, err := ecsConfig.Credentials()
+	assert.NoError(t, err, "Unexpected error getting credentials without a region")
+	verifyCredentials(t, creds, awsAccessKey, awsSecretKey)
+}
+
+func TestCredentialsFromAssumeRoleProfile(t *testing.T) {
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.AwsProfile = assumeRoleName
+
+	os.Setenv("AWS_CONFIG_FILE", "aws_config_example.ini")
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "aws_credentials_example.ini")
+	defer os.Clearenv()
+
+	server := newAssumeRoleServer()
+	defer server.Close()
+
+	startingConfig := aws.Config{}
+	startingConfig.Endpoint = aws.String(server.URL)
+	startingConfig.DisableSSL = aws.Bool(true)
+
+	creds, err := ecsConfig.credentialsWithConfig(startingConfig)
+	assert.NoError(t, err, "Unexpected error getting credentials")
+	verifyCredentials(t, creds, assumeRoleAccessKey, assumeRoleSecretKey)
+}
+
+func TestCredentialsFromAssumeRoleProfileWithCustomEndpoint(t *testing.T) {
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.AwsProfile = assumeRoleName
+
+	os.Setenv("AWS_CONFIG_FILE", "aws_config_example.ini")
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "aws_credentials_example.ini")
+	defer os.Clearenv()
+
+	server := newAssumeRoleServer()
+	defer server.Close()
+
+	// The assume role call must use the custom STS endpoint, as it does in ToAWSSession.
+	ecsConfig.Endpoints = map[string]string{endpoints.StsServiceID: server.URL}
+
+	creds, err := ecsConfig.Credentials()
+	assert.NoError(t, err, "Unexpected error getting credentials")
+	verifyCredentials(t, creds, assumeRoleAccessKey, assumeRoleSecretKey)
+}
+
+func TestCredentialsFromEnvVariables(t *testing.T) {
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.Region = region
+
+	os.Setenv("AWS_ACCESS_KEY_ID", envAwsAccessKey)
+	os.Setenv("AWS_SECRET_ACCESS_KEY", envAwsSecretKey)
+	defer os.Clearenv()
+
+	creds, err := ecsConfig.Credentials()
+	assert.NoError(t, err, "Unexpected error getting credentials")
+	verifyCredentials(t, creds, envAwsAccessKey, envAwsSecretKey)
+}
+
+func TestAPITimeoutInSession(t *testing.T) {
+	os.Clearenv()
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.Region = region
+	ecsConfig.APITimeout = "30s"
+
+	awsSession, err := ecsConfig.ToAWSSession()
+	assert.NoError(t, err, "Unexpected error generating a new session")
+	assert.Equal(t, 30*time.Second, awsSession.Config.HTTPClient.Timeout, "Expected HTTP client timeout to match")
+}
+
+func TestExportEnvironment(t *testing.T) {
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.Region = region
+	ecsConfig.AwsAccessKey = awsAccessKey
+	ecsConfig.AwsSecretKey = awsSecretKey
+	os.Clearenv()
+
+	exports, err := ecsConfig.ExportEnvironment(false)
+	assert.NoError(t, err, "Unexpected error exporting environment")
+	expected := []string{
+		"export AWS_ACCESS_KEY_ID='" + awsAccessKey + "'",
+		"export AWS_SECRET_ACCESS_KEY='" + awsSecretKey + "'",
+		"export AWS_REGION='" + region + "'",
+	}
+	assert.Equal(t, expected, exports, "Export lines mismatch")
+}
+
+func TestExportEnvironmentWithSessionToken(t *testing.T) {
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.Region = region
+
+	os.Setenv("AWS_ACCESS_KEY_ID", envAwsAccessKey)
+	os.Setenv("AWS_SECRET_ACCESS_KEY", envAwsSecretKey)
+	os.Setenv("AWS_SESSION_TOKEN", "token")
+	defer os.Clearenv()
+
+	exports, err := ecsConfig.ExportEnvironment(false)
+	assert.NoError(t, err, "Unexpected error exporting environment")
+	expected := []string{
+		"export AWS_ACCESS_KEY_ID='" + envAwsAccessKey + "'",
+		"export AWS_SECRET_ACCESS_KEY='" + envAwsSecretKey + "'",
+		"export AWS_SESSION_TOKEN='token'",
+		"export AWS_REGION='" + region + "'",
+	}
+	assert.Equal(t, expected, exports, "Export lines mismatch")
+
+	exports, err = ecsConfig.ExportEnvironment(true)
+	assert.NoError(t, err, "Unexpected error exporting environment")
+	expected = []string{
+		"export AWS_ACCESS_KEY_ID='" + envAwsAccessKey + "'",
+		"export AWS_SECRET_ACCESS_KEY='********'",
+		"export AWS_SESSION_TOKEN='********'",
+		"export AWS_REGION='" + region + "'",
+	}
+	assert.Equal(t, expected, exports, "Redacted export lines mismatch")
+}
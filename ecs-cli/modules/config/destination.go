@@ -14,10 +14,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
-	"github.com/Sirupsen/logrus"
+	ecscli "github.com/aws/amazon-ecs-cli/ecs-cli/modules/commands"
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/utils"
 )
 
@@ -32,7 +33,6 @@ type Destination struct {
 func GetFilePermissions(fileName string) (*os.FileMode, error) {
 	fileInfo, err := os.Stat(fileName)
 	if err != nil {
-		logrus.Warnf("Error getting permissions of file: %s", fileName)
 		return nil, err
 	}
 
@@ -40,11 +40,21 @@ func GetFilePermissions(fileName string) (*os.FileMode, error) {
 	return &mode, nil
 }
 
-// newDefaultDestination creates a new Destination object.
+// newDefaultDestination creates a new Destination object. The config directory is
+// $ECS_CONFIG_HOME when set, and ~/.ecs otherwise.
 func newDefaultDestination() (*Destination, error) {
+	if configHome := os.Getenv(ecscli.ConfigHomeEnvVar); configHome != "" {
+		// The config directory is created with the permissions of its parent, as ~/.ecs is with the home directory's.
+		mode, err := GetFilePermissions(filepath.Dir(configHome))
+		if err != nil {
+			return nil, err
+		}
+		return &Destination{Path: configHome, Mode: mode}, nil
+	}
+
 	homeDir, err := utils.GetHomeDir()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("Unable to determine home directory; set HOME or %s", ecscli.ConfigHomeEnvVar)
 	}
 	mode, err := GetFilePermissions(homeDir)
 	if err != nil {
@@ -16,6 +16,7 @@ package config
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -40,3 +41,29 @@ func TestNewDefaultDestination(t *testing.T) {
 	}, "Invalid suffix for ecs config path")
 	assert.True(t, dest.Mode.IsDir(), "Expected user home directory to be in directory mode")
 }
+
+func TestNewDefaultDestinationWhenHomeIsUnset(t *testing.T) {
+	os.Clearenv()
+
+	_, err := newDefaultDestination()
+	assert.Error(t, err, "Expected error when home directory can not be determined")
+	assert.Contains(t, err.Error(), "ECS_CONFIG_HOME", "Expected error to mention the config home override")
+}
+
+func TestNewDefaultDestinationWithConfigHome(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "test")
+	if err != nil {
+		t.Fatal("Error while creating the dummy ecs config directory")
+	}
+	defer os.Remove(tempDirName)
+
+	os.Clearenv()
+	configHome := filepath.Join(tempDirName, "ecs-config")
+	os.Setenv("ECS_CONFIG_HOME", configHome)
+	defer os.Clearenv()
+
+	dest, err := newDefaultDestination()
+	assert.NoError(t, err, "Unexpected error creating new config path")
+	assert.Equal(t, configHome, dest.Path, "Expected config path to be ECS_CONFIG_HOME")
+	assert.True(t, dest.Mode.IsDir(), "Expected config home parent to be in directory mode")
+}
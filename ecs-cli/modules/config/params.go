@@ -14,12 +14,18 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients"
 	ecscli "github.com/aws/amazon-ecs-cli/ecs-cli/modules/commands"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
 	"github.com/urfave/cli"
 )
 
@@ -30,6 +36,9 @@ type CliParams struct {
 	ComposeProjectNamePrefix string
 	ComposeServiceNamePrefix string
 	CFNStackNamePrefix       string
+	KeyPairName              string
+	InstanceType             string
+	AwsAccountID             string
 }
 
 // GetCfnStackName <cfn_stack_name_prefix> + <cluster_name>
@@ -37,8 +46,23 @@ func (p *CliParams) GetCfnStackName() string {
 	return fmt.Sprintf("%s%s", p.CFNStackNamePrefix, p.Cluster)
 }
 
+// ValidateClusterConfigured returns an error if no cluster is configured. The config may omit the
+// cluster for cluster-less commands, and ECS would resolve the empty name to the 'default' cluster.
+func (p *CliParams) ValidateClusterConfigured() error {
+	if p.Cluster == "" {
+		return fmt.Errorf("Please configure a cluster using the configure command or the '--%s' flag", ecscli.ClusterFlag)
+	}
+	return nil
+}
+
 // NewCliParams creates a new ECSParams object from the config file.
-func NewCliParams(context *cli.Context, rdwr ReadWriter) (*CliParams, error) {
+func NewCliParams(cliContext *cli.Context, rdwr ReadWriter) (*CliParams, error) {
+	return NewCliParamsWithContext(context.Background(), cliContext, rdwr)
+}
+
+// NewCliParamsWithContext is NewCliParams with a context that cancels the remote lookups,
+// such as the account id check, made while resolving the params.
+func NewCliParamsWithContext(ctx context.Context, cliContext *cli.Context, rdwr ReadWriter) (*CliParams, error) {
 	ecsConfig, err := rdwr.GetConfig()
 	if err != nil {
 		logrus.Error("Error loading config: ", err)
@@ -64,17 +88,17 @@ func NewCliParams(context *cli.Context, rdwr ReadWriter) (*CliParams, error) {
 		ecsConfig.Cluster = clusterFromEnv
 	}
 	// First try to find the flag in the global string, then try to find the flag locally
-	if clusterFromFlag := context.GlobalString(ecscli.ClusterFlag); clusterFromFlag != "" {
+	if clusterFromFlag := cliContext.GlobalString(ecscli.ClusterFlag); clusterFromFlag != "" {
 		ecsConfig.Cluster = clusterFromFlag
-	} else if clusterFromFlag := context.String(ecscli.ClusterFlag); clusterFromFlag != "" {
+	} else if clusterFromFlag := cliContext.String(ecscli.ClusterFlag); clusterFromFlag != "" {
 		ecsConfig.Cluster = clusterFromFlag
 	}
 
 	//--region flag has the highest precedence to set ecs-cli region config.
 	// First try to find the flag in the global string, then try to find the flag locally
-	if regionFromFlag := context.GlobalString(ecscli.RegionFlag); regionFromFlag != "" {
+	if regionFromFlag := cliContext.GlobalString(ecscli.RegionFlag); regionFromFlag != "" {
 		ecsConfig.Region = regionFromFlag
-	} else if regionFromFlag := context.String(ecscli.RegionFlag); regionFromFlag != "" {
+	} else if regionFromFlag := cliContext.String(ecscli.RegionFlag); regionFromFlag != "" {
 		ecsConfig.Region = regionFromFlag
 	}
 
@@ -83,11 +107,50 @@ func NewCliParams(context *cli.Context, rdwr ReadWriter) (*CliParams, error) {
 		return nil, err
 	}
 
+	if err := ecsConfig.validateAllowedRegion(aws.StringValue(svcSession.Config.Region)); err != nil {
+		return nil, err
+	}
+
+	if ecsConfig.AwsAccountID != "" {
+		if err := verifyAccountID(ctx, ecsConfig.AwsAccountID, newSTSClient(svcSession)); err != nil {
+			return nil, err
+		}
+	}
+
 	return &CliParams{
 		Cluster:                  ecsConfig.Cluster,
 		Session:                  svcSession,
 		ComposeProjectNamePrefix: ecsConfig.ComposeProjectNamePrefix,
 		ComposeServiceNamePrefix: ecsConfig.ComposeServiceNamePrefix,
 		CFNStackNamePrefix:       ecsConfig.CFNStackNamePrefix,
+		KeyPairName:              ecsConfig.KeyPairName,
+		InstanceType:             ecsConfig.InstanceType,
+		AwsAccountID:             ecsConfig.AwsAccountID,
 	}, nil
 }
+
+// newSTSClient is overridden in tests to verify the configured account id against a mock.
+var newSTSClient = func(svcSession *session.Session) stsiface.STSAPI {
+	client := sts.New(svcSession)
+	client.Handlers.Build.PushBackNamed(clients.CustomUserAgentHandler())
+	return client
+}
+
+// verifyAccountID returns an error if the caller identity does not belong to the expected account.
+func verifyAccountID(ctx context.Context, expectedAccountID string, stsClient stsiface.STSAPI) error {
+	req, resp := stsClient.GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	req.HTTPRequest = req.HTTPRequest.WithContext(ctx)
+	// The SDK retries failed sends, so stop it from retrying once the context is done.
+	req.Handlers.Retry.PushBack(func(r *request.Request) {
+		if ctx.Err() != nil {
+			r.Retryable = aws.Bool(false)
+		}
+	})
+	if err := req.Send(); err != nil {
+		return fmt.Errorf("Unable to verify the AWS account id: %s", err.Error())
+	}
+	if accountID := aws.StringValue(resp.Account); accountID != expectedAccountID {
+		return fmt.Errorf("The AWS credentials belong to account '%s', but the ECS CLI configuration expects account '%s'", accountID, expectedAccountID)
+	}
+	return nil
+}
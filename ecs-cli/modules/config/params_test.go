@@ -14,12 +14,23 @@
 package config
 
 import (
+	"context"
 	"flag"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients/aws/sts/mock/sdk"
 	command "github.com/aws/amazon-ecs-cli/ecs-cli/modules/commands"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client/metadata"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli"
 )
@@ -28,9 +39,13 @@ import (
 // field whenperforming read.
 type mockReadWriter struct {
 	isKeyPresentValue bool
+	cliConfig         *CliConfig
 }
 
 func (rdwr *mockReadWriter) GetConfig() (*CliConfig, error) {
+	if rdwr.cliConfig != nil {
+		return rdwr.cliConfig, nil
+	}
 	return NewCliConfig(clusterName), nil
 }
 
@@ -147,6 +162,166 @@ func TestNewCliParamsWhenPrefixKeysAreNotPresent(t *testing.T) {
 	assert.Equal(t, command.CFNStackNamePrefixDefaultValue, params.CFNStackNamePrefix, "Expected CFNStackNamePrefix to match")
 }
 
+func TestNewCliParamsWithoutCluster(t *testing.T) {
+	os.Setenv("AWS_CONFIG_FILE", "aws_config_example.ini")
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", "aws_credentials_example.ini")
+	defer os.Clearenv()
+
+	context, _ := setupTest(t)
+
+	// Only a profile is configured; the region must come from the profile
+	ecsConfig := NewCliConfig("")
+	ecsConfig.AwsProfile = customProfileName
+	rdwr := &mockReadWriter{cliConfig: ecsConfig}
+
+	params, err := NewCliParams(context, rdwr)
+	assert.NoError(t, err, "Unexpected error when cluster is not configured")
+	assert.Empty(t, params.Cluster, "Expected cluster to be empty")
+	assert.Equal(t, customAwsRegion, aws.StringValue(params.Session.Config.Region), "Region should match the profile")
+	assert.Error(t, params.ValidateClusterConfigured(), "Expected error when cluster is not configured")
+}
+
+func TestNewCliParamsWithRegionInAllowedRegions(t *testing.T) {
+	os.Setenv("AWS_ACCESS_KEY", "AKIDEXAMPLE")
+	os.Setenv("AWS_SECRET_KEY", "SECRET")
+	defer os.Clearenv()
+
+	context := defaultConfig()
+
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.AllowedRegions = []string{"us-west-2", "us-east-1"}
+	rdwr := &mockReadWriter{cliConfig: ecsConfig}
+
+	params, err := NewCliParams(context, rdwr)
+	assert.NoError(t, err, "Unexpected error when region is in the allowed regions")
+	assert.Equal(t, "us-east-1", aws.StringValue(params.Session.Config.Region), "Region should match")
+}
+
+func TestNewCliParamsWithRegionNotInAllowedRegions(t *testing.T) {
+	os.Setenv("AWS_ACCESS_KEY", "AKIDEXAMPLE")
+	os.Setenv("AWS_SECRET_KEY", "SECRET")
+	defer os.Clearenv()
+
+	context := defaultConfig()
+
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.AllowedRegions = []string{"us-west-2", "eu-west-1"}
+	rdwr := &mockReadWriter{cliConfig: ecsConfig}
+
+	_, err := NewCliParams(context, rdwr)
+	assert.Error(t, err, "Expected error when region is not in the allowed regions")
+	assert.Contains(t, err.Error(), "us-west-2, eu-west-1", "Expected error to list the allowed regions")
+}
+
+func TestNewCliParamsWithMatchingAccountID(t *testing.T) {
+	os.Setenv("AWS_ACCESS_KEY", "AKIDEXAMPLE")
+	os.Setenv("AWS_SECRET_KEY", "SECRET")
+	defer os.Clearenv()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockSts, restore := setupMockSTSClient(ctrl)
+	defer restore()
+
+	mockSts.EXPECT().GetCallerIdentityRequest(gomock.Any()).Return(callerIdentityRequest("123456789012"))
+
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.AwsAccountID = "123456789012"
+	rdwr := &mockReadWriter{cliConfig: ecsConfig}
+
+	_, err := NewCliParams(defaultConfig(), rdwr)
+	assert.NoError(t, err, "Unexpected error when account id matches")
+}
+
+func TestNewCliParamsWithMismatchingAccountID(t *testing.T) {
+	os.Setenv("AWS_ACCESS_KEY", "AKIDEXAMPLE")
+	os.Setenv("AWS_SECRET_KEY", "SECRET")
+	defer os.Clearenv()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockSts, restore := setupMockSTSClient(ctrl)
+	defer restore()
+
+	mockSts.EXPECT().GetCallerIdentityRequest(gomock.Any()).Return(callerIdentityRequest("210987654321"))
+
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.AwsAccountID = "123456789012"
+	rdwr := &mockReadWriter{cliConfig: ecsConfig}
+
+	_, err := NewCliParams(defaultConfig(), rdwr)
+	assert.Error(t, err, "Expected error when account id does not match")
+	assert.Contains(t, err.Error(), "123456789012", "Expected error to name the configured account id")
+	assert.Contains(t, err.Error(), "210987654321", "Expected error to name the account id of the credentials")
+}
+
+func TestNewCliParamsWithoutAccountIDDoesNotCallSTS(t *testing.T) {
+	os.Setenv("AWS_ACCESS_KEY", "AKIDEXAMPLE")
+	os.Setenv("AWS_SECRET_KEY", "SECRET")
+	defer os.Clearenv()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// No expectations are set, so any call to STS fails the test
+	_, restore := setupMockSTSClient(ctrl)
+	defer restore()
+
+	_, err := NewCliParams(defaultConfig(), &mockReadWriter{})
+	assert.NoError(t, err, "Unexpected error when account id is not configured")
+}
+
+func TestNewCliParamsWithContextCancelled(t *testing.T) {
+	os.Setenv("AWS_ACCESS_KEY", "AKIDEXAMPLE")
+	os.Setenv("AWS_SECRET_KEY", "SECRET")
+	defer os.Clearenv()
+
+	// The server never responds, so the account id check can only finish by being cancelled.
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	original := newSTSClient
+	newSTSClient = func(s *session.Session) stsiface.STSAPI {
+		return sts.New(s, &aws.Config{Endpoint: aws.String(server.URL)})
+	}
+	defer func() { newSTSClient = original }()
+
+	ecsConfig := NewCliConfig(clusterName)
+	ecsConfig.AwsAccountID = "123456789012"
+	rdwr := &mockReadWriter{cliConfig: ecsConfig}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	_, err := NewCliParamsWithContext(ctx, defaultConfig(), rdwr)
+	assert.Error(t, err, "Expected error when the context is cancelled")
+	assert.Contains(t, err.Error(), "context canceled", "Expected error to report the cancellation")
+	assert.True(t, time.Since(start) < 5*time.Second, "Expected the account id check to abort promptly")
+}
+
+// callerIdentityRequest returns a GetCallerIdentity request that succeeds with the given account
+// id without sending anything.
+func callerIdentityRequest(accountID string) (*request.Request, *sts.GetCallerIdentityOutput) {
+	output := &sts.GetCallerIdentityOutput{Account: aws.String(accountID)}
+	req := request.New(aws.Config{}, metadata.ClientInfo{}, request.Handlers{}, nil,
+		&request.Operation{Name: "GetCallerIdentity"}, &sts.GetCallerIdentityInput{}, output)
+	return req, output
+}
+
+// setupMockSTSClient injects a mock STS client and returns a func that restores the original.
+func setupMockSTSClient(ctrl *gomock.Controller) (*mock_stsiface.MockSTSAPI, func()) {
+	mockSts := mock_stsiface.NewMockSTSAPI(ctrl)
+	original := newSTSClient
+	newSTSClient = func(s *session.Session) stsiface.STSAPI {
+		return mockSts
+	}
+	return mockSts, func() { newSTSClient = original }
+}
+
 func defaultConfig() *cli.Context {
 	globalSet := flag.NewFlagSet("ecs-cli", 0)
 	globalContext := cli.NewContext(nil, globalSet, nil)
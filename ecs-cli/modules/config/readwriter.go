@@ -14,16 +14,22 @@
 package config
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/go-ini/ini"
 )
 
 const (
-	configFileName = "config"
-	configFileMode = os.FileMode(0600)
+	configFileName     = "config"
+	configFileMode     = os.FileMode(0600)
+	backupFileSuffix   = ".bak"
+	backupTimestampFmt = "20060102T150405.000000000"
 )
 
 // ReadWriter interface has methods to read and write ecs-cli config to and from the config file.
@@ -46,6 +52,24 @@ type ReadWriter interface {
 // compose-project-name-prefix = ecscompose-
 // compose-service-name-prefix =
 // cfn-stack-name-prefix = ecs-cli-
+// allowed_regions = us-west-2,eu-west-1
+// aws_account_id = 123456789012
+// key_pair = my-key-pair
+// instance_type = t2.medium
+// config_backups = 3
+// color = auto
+// quiet = false
+// api_timeout = 30s
+// region_from_name = ^app-([a-z0-9-]+)$
+//
+// [endpoints]
+// ecs = http://localhost:4566
+//
+// [region_aliases]
+// primary = us-east-1
+//
+// The keys after cfn-stack-name-prefix and the endpoints and region_aliases sections are
+// optional, and are not written by the configure command.
 
 type IniReadWriter struct {
 	*Destination
@@ -67,7 +91,9 @@ func NewReadWriter() (*IniReadWriter, error) {
 	return &IniReadWriter{Destination: dest, cfg: iniCfg}, nil
 }
 
-// GetConfig gets the ecs-cli config object from the config file.
+// GetConfig gets the ecs-cli config object from the config file. The cluster, region and
+// aws_profile values may reference environment variables (e.g. cluster = app-${ENVIRONMENT});
+// they are expanded here on read, so the config file keeps the references.
 func (rdwr *IniReadWriter) GetConfig() (*CliConfig, error) {
 	to := new(CliConfig)
 	err := rdwr.cfg.MapTo(to)
@@ -75,11 +101,38 @@ func (rdwr *IniReadWriter) GetConfig() (*CliConfig, error) {
 		return nil, err
 	}
 
+	to.Cluster = os.ExpandEnv(to.Cluster)
+	to.Region = os.ExpandEnv(to.Region)
+	to.AwsProfile = os.ExpandEnv(to.AwsProfile)
+
+	if section, err := rdwr.cfg.GetSection(endpointsSectionKey); err == nil {
+		to.Endpoints = section.KeysHash()
+	}
+	if section, err := rdwr.cfg.GetSection(regionAliasesSectionKey); err == nil {
+		to.RegionAliases = section.KeysHash()
+	}
+
+	if err := to.validateColor(); err != nil {
+		return nil, err
+	}
+	if err := to.validateEndpoints(); err != nil {
+		return nil, err
+	}
+	if _, err := to.getAPITimeout(); err != nil {
+		return nil, err
+	}
+	if _, err := to.getConfigBackups(); err != nil {
+		return nil, err
+	}
+	if _, err := to.getRegionFromNamePattern(); err != nil {
+		return nil, err
+	}
+
 	return to, nil
 }
 
-// IsInitialized returns true if the config file can be read and if all the key config fields
-// have been initialized.
+// IsInitialized returns true if the config file can be read and if either the cluster or a
+// region/profile for cluster-less commands has been initialized.
 func (rdwr *IniReadWriter) IsInitialized() (bool, error) {
 	to := new(CliConfig)
 	err := rdwr.cfg.MapTo(to)
@@ -87,7 +140,7 @@ func (rdwr *IniReadWriter) IsInitialized() (bool, error) {
 		return false, err
 	}
 
-	if to.Cluster == "" {
+	if to.Cluster == "" && to.Region == "" && to.AwsProfile == "" {
 		return false, nil
 	}
 
@@ -101,7 +154,40 @@ func (rdwr *IniReadWriter) IsKeyPresent(section, key string) bool {
 
 // ReadFrom initializes the ini object from an existing ecs-cli config object.
 func (rdwr *IniReadWriter) ReadFrom(ecsConfig *CliConfig) error {
-	return rdwr.cfg.ReflectFrom(ecsConfig)
+	// The configure command may be run without a cluster, e.g. to change only the region;
+	// keep the existing cluster in that case.
+	existingCluster := rdwr.cfg.Section(ecsSectionKey).Key(clusterKey).String()
+	if err := rdwr.cfg.ReflectFrom(ecsConfig); err != nil {
+		return err
+	}
+	if ecsConfig.Cluster == "" {
+		rdwr.cfg.Section(ecsSectionKey).Key(clusterKey).SetValue(existingCluster)
+	}
+
+	// Endpoints and region aliases are not set by the configure command; keep the existing
+	// sections unless new ones are given.
+	if err := rdwr.replaceSection(endpointsSectionKey, ecsConfig.Endpoints); err != nil {
+		return err
+	}
+	return rdwr.replaceSection(regionAliasesSectionKey, ecsConfig.RegionAliases)
+}
+
+// replaceSection replaces the keys of the named section, unless no keys are given.
+func (rdwr *IniReadWriter) replaceSection(name string, keys map[string]string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	rdwr.cfg.DeleteSection(name)
+	section, err := rdwr.cfg.NewSection(name)
+	if err != nil {
+		return err
+	}
+	for key, value := range keys {
+		if _, err := section.NewKey(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Save saves the config to a config file.
@@ -114,38 +200,81 @@ func (rdwr *IniReadWriter) Save(dest *Destination) error {
 
 	path := configPath(dest)
 
-	// If config file exists, set permissions first, because we may be writing creds.
 	if _, err := os.Stat(path); err == nil {
-		err = os.Chmod(path, configFileMode)
+		to := new(CliConfig)
+		if err := rdwr.cfg.MapTo(to); err != nil {
+			return err
+		}
+		backups, err := to.getConfigBackups()
 		if err != nil {
-			logrus.Errorf("Unable to chmod %s to mode %s", path, configFileMode)
 			return err
 		}
+		if backups > 0 {
+			if err := backupConfig(path, backups); err != nil {
+				return fmt.Errorf("Unable to back up %s: %s", path, err.Error())
+			}
+		}
+	}
+
+	// Write to a temporary file in the same directory and rename it over the config file, so
+	// that the config file is never left partially written. TempFile creates the file with
+	// mode 0600, because we may be writing creds.
+	configFile, err := ioutil.TempFile(dest.Path, configFileName+".tmp")
+	if err != nil {
+		return fmt.Errorf("Unable to create a temporary config file in %s: %s", dest.Path, err.Error())
 	}
+	tmpPath := configFile.Name()
+	defer os.Remove(tmpPath)
 
-	// Open the file, optionally creating it with our desired permissions.
-	// This will let us pass it (as io.Writer) to go-ini but let us control the file.
-	configFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, configFileMode)
+	_, err = rdwr.cfg.WriteTo(configFile)
+	if closeErr := configFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to write config to %s: %s", tmpPath, err.Error())
+	}
+	if err = os.Chmod(tmpPath, configFileMode); err != nil {
+		return fmt.Errorf("Unable to chmod %s to mode %s: %s", tmpPath, configFileMode, err.Error())
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("Unable to replace %s: %s", path, err.Error())
+	}
 
-	// Truncate the file in case the earlier contents are longer than the new
-	// contents, so there will not be any trash at the end of the file
-	configFile.Truncate(0)
+	return nil
+}
 
+// backupConfig copies the config file at path to a timestamped backup next to it, and removes
+// the oldest backups so that no more than the given number are kept.
+func backupConfig(path string, backups int) error {
+	contents, err := ioutil.ReadFile(path)
 	if err != nil {
-		logrus.Errorf("Unable to open/create %s with mode %s", path, configFileMode)
 		return err
 	}
-	defer configFile.Close()
+	backupPath := path + "." + time.Now().UTC().Format(backupTimestampFmt) + backupFileSuffix
+	if err := ioutil.WriteFile(backupPath, contents, configFileMode); err != nil {
+		return err
+	}
 
-	_, err = rdwr.cfg.WriteTo(configFile)
+	backupPaths, err := filepath.Glob(path + ".*" + backupFileSuffix)
 	if err != nil {
-		logrus.Errorf("Unable to write config to %s", path)
 		return err
 	}
-
+	// The timestamps sort lexicographically, so the oldest backups come first.
+	sort.Strings(backupPaths)
+	for len(backupPaths) > backups {
+		if err := os.Remove(backupPaths[0]); err != nil {
+			return err
+		}
+		backupPaths = backupPaths[1:]
+	}
 	return nil
 }
 
+// ConfigPath returns the path of the config file used by the IniReadWriter.
+func (rdwr *IniReadWriter) ConfigPath() string {
+	return configPath(rdwr.Destination)
+}
+
 func configPath(dest *Destination) string {
 	return filepath.Join(dest.Path, configFileName)
 }
@@ -16,6 +16,7 @@ package config
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -60,7 +61,7 @@ func saveConfigWithCluster(t *testing.T, parser *IniReadWriter, dest *Destinatio
 
 func saveConfig(t *testing.T, parser *IniReadWriter, dest *Destination, sectionKeys *SectionKeys) {
 	// Create a new config file
-	newConfig := &CliConfig{sectionKeys}
+	newConfig := &CliConfig{SectionKeys: sectionKeys}
 	err := parser.ReadFrom(newConfig)
 	assert.NoError(t, err, "Could not create config from struct")
 
@@ -99,6 +100,141 @@ func TestConfigPermissions(t *testing.T) {
 	confirmConfigMode(t, path, configFileMode)
 }
 
+func TestConfigBackups(t *testing.T) {
+	configContents := `[ecs]
+cluster = test
+config_backups = 2
+`
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	path := configPath(dest)
+	err = ioutil.WriteFile(path, []byte(configContents), *dest.Mode)
+	assert.NoError(t, err)
+
+	parser := setupParser(t, dest, true)
+	saveConfigWithCluster(t, parser, dest)
+
+	backups, err := filepath.Glob(path + ".*" + backupFileSuffix)
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1, "Expected a backup of the config file")
+	backupContents, err := ioutil.ReadFile(backups[0])
+	assert.NoError(t, err)
+	assert.Equal(t, configContents, string(backupContents), "Expected backup to have the previous contents")
+	confirmConfigMode(t, backups[0], configFileMode)
+
+	// Only the 2 most recent backups are kept
+	saveConfigWithCluster(t, parser, dest)
+	saveConfigWithCluster(t, parser, dest)
+	prunedBackups, err := filepath.Glob(path + ".*" + backupFileSuffix)
+	assert.NoError(t, err)
+	assert.Len(t, prunedBackups, 2, "Expected old backups to be pruned")
+	assert.NotContains(t, prunedBackups, backups[0], "Expected the oldest backup to be removed")
+}
+
+func TestConfigWithoutBackups(t *testing.T) {
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	parser := setupParser(t, dest, false)
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	saveConfigWithCluster(t, parser, dest)
+	saveConfigWithCluster(t, parser, dest)
+
+	backups, err := filepath.Glob(configPath(dest) + ".*" + backupFileSuffix)
+	assert.NoError(t, err)
+	assert.Empty(t, backups, "Expected no backups unless config_backups is set")
+}
+
+func TestConfigBackupsWithInvalidValues(t *testing.T) {
+	for _, backups := range []string{"five", "-1", "2.5"} {
+		dest, err := newMockDestination()
+		assert.NoError(t, err, "Error creating mock config destination")
+
+		err = os.MkdirAll(dest.Path, *dest.Mode)
+		assert.NoError(t, err, "Could not create config directory")
+
+		configContents := "[ecs]\ncluster = test\nconfig_backups = " + backups + "\n"
+		err = ioutil.WriteFile(configPath(dest), []byte(configContents), *dest.Mode)
+		assert.NoError(t, err)
+
+		parser := setupParser(t, dest, true)
+		_, err = parser.GetConfig()
+		assert.Error(t, err, "Expected error reading config_backups %s", backups)
+
+		err = parser.Save(dest)
+		assert.Error(t, err, "Expected error saving with config_backups %s", backups)
+		contents, err := ioutil.ReadFile(configPath(dest))
+		assert.NoError(t, err)
+		assert.Equal(t, configContents, string(contents), "Expected config file to be unchanged")
+		os.RemoveAll(dest.Path)
+	}
+}
+
+func TestSaveLeavesNoTemporaryFiles(t *testing.T) {
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	parser := setupParser(t, dest, false)
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	saveConfigWithCluster(t, parser, dest)
+	saveConfigWithCluster(t, parser, dest)
+
+	files, err := ioutil.ReadDir(dest.Path)
+	assert.NoError(t, err, "Unable to list config directory")
+	assert.Len(t, files, 1, "Expected only the config file in the config directory")
+	assert.Equal(t, configFileName, files[0].Name(), "Expected only the config file in the config directory")
+	confirmConfigMode(t, configPath(dest), configFileMode)
+}
+
+func TestSaveRemovesTemporaryFileWhenRenameFails(t *testing.T) {
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	parser := setupParser(t, dest, false)
+
+	// A directory in place of the config file makes the rename fail.
+	err = os.MkdirAll(configPath(dest), *dest.Mode)
+	assert.NoError(t, err, "Could not create a directory at the config path")
+
+	defer os.RemoveAll(dest.Path)
+
+	err = parser.ReadFrom(NewCliConfig(testClusterName))
+	assert.NoError(t, err, "Could not create config from struct")
+
+	err = parser.Save(dest)
+	assert.Error(t, err, "Expected error when the config file cannot be replaced")
+
+	tmpFiles, err := filepath.Glob(filepath.Join(dest.Path, configFileName+".tmp*"))
+	assert.NoError(t, err, "Unable to list config directory")
+	assert.Empty(t, tmpFiles, "Expected the temporary config file to be removed")
+}
+
+func TestConfigPath(t *testing.T) {
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	defer os.RemoveAll(dest.Path)
+
+	parser := setupParser(t, dest, false)
+	assert.Equal(t, filepath.Join(dest.Path, configFileName), parser.ConfigPath(), "Config path mismatch.")
+}
+
 func confirmConfigMode(t *testing.T, path string, expected os.FileMode) {
 	info, err := os.Stat(path)
 	assert.NoError(t, err, "Unable to stat config file %s", path)
@@ -135,6 +271,28 @@ func TestNewConfigReadWriter(t *testing.T) {
 	assert.Empty(t, readConfig.CFNStackNamePrefix, "CFNStackNamePrefix should be empty.")
 }
 
+func TestIsInitializedWithoutCluster(t *testing.T) {
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	parser := setupParser(t, dest, false)
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	saveConfig(t, parser, dest, &SectionKeys{AwsProfile: testAWSProfile})
+
+	// A profile without a cluster is enough for cluster-less commands
+	parser = setupParser(t, dest, true)
+
+	readConfig, err := parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Empty(t, readConfig.Cluster, "Expected cluster to be empty")
+	assert.Equal(t, testAWSProfile, readConfig.AwsProfile, "Profile mismatch in config.")
+}
+
 func TestMissingPrefixes(t *testing.T) {
 	configContentsNoPrefixes := `[ecs]
 cluster = test
@@ -162,6 +320,270 @@ aws_secret_access_key =
 	assert.False(t, parser.IsKeyPresent(ecsSectionKey, composeProjectNamePrefixKey), "Compose project name prefix should not exist in config")
 }
 
+func TestAllowedRegions(t *testing.T) {
+	configContents := `[ecs]
+cluster = test
+region = us-west-2
+allowed_regions = us-west-2, us-east-1
+`
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	err = ioutil.WriteFile(dest.Path+"/"+configFileName, []byte(configContents), *dest.Mode)
+	assert.NoError(t, err)
+
+	parser := setupParser(t, dest, true)
+	readConfig, err := parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Equal(t, []string{"us-west-2", "us-east-1"}, readConfig.AllowedRegions, "Allowed regions mismatch in config.")
+
+	// Re-running configure must not drop the allowlist
+	saveConfigWithCluster(t, parser, dest)
+	parser = setupParser(t, dest, true)
+	readConfig, err = parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Equal(t, []string{"us-west-2", "us-east-1"}, readConfig.AllowedRegions, "Allowed regions should be preserved on save.")
+}
+
+func TestGetConfigExpandsEnvironmentVariables(t *testing.T) {
+	configContents := `[ecs]
+cluster = app-${ECS_TEST_ENVIRONMENT}
+aws_profile = $ECS_TEST_ENVIRONMENT
+region = us-west-2
+`
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	err = ioutil.WriteFile(dest.Path+"/"+configFileName, []byte(configContents), *dest.Mode)
+	assert.NoError(t, err)
+
+	os.Setenv("ECS_TEST_ENVIRONMENT", "staging")
+	defer os.Unsetenv("ECS_TEST_ENVIRONMENT")
+
+	parser := setupParser(t, dest, true)
+	readConfig, err := parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Equal(t, "app-staging", readConfig.Cluster, "Cluster name should be expanded.")
+	assert.Equal(t, "staging", readConfig.AwsProfile, "Profile should be expanded.")
+	assert.Equal(t, "us-west-2", readConfig.Region, "Region should be unchanged.")
+}
+
+func TestEndpoints(t *testing.T) {
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	parser := setupParser(t, dest, false)
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	endpoints := map[string]string{
+		"ecs":            "http://localhost:4566",
+		"cloudformation": "http://localhost:4581",
+	}
+	newConfig := NewCliConfig(testClusterName)
+	newConfig.Endpoints = endpoints
+	err = parser.ReadFrom(newConfig)
+	assert.NoError(t, err, "Could not create config from struct")
+	err = parser.Save(dest)
+	assert.NoError(t, err, "Could not save config file")
+
+	parser = setupParser(t, dest, true)
+	readConfig, err := parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Equal(t, endpoints, readConfig.Endpoints, "Endpoints mismatch in config.")
+
+	// Saving a config without endpoints keeps the ones in the file
+	saveConfigWithCluster(t, parser, dest)
+	parser = setupParser(t, dest, true)
+	readConfig, err = parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Equal(t, endpoints, readConfig.Endpoints, "Endpoints should be preserved on save.")
+}
+
+func TestEndpointsWithInvalidURL(t *testing.T) {
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	configContents := "[ecs]\ncluster = test\n\n[endpoints]\necs = localhost\n"
+	err = ioutil.WriteFile(dest.Path+"/"+configFileName, []byte(configContents), *dest.Mode)
+	assert.NoError(t, err)
+
+	parser := setupParser(t, dest, true)
+	_, err = parser.GetConfig()
+	assert.Error(t, err, "Expected error for an endpoint that is not an absolute URL")
+}
+
+func TestRegionAliases(t *testing.T) {
+	configContents := `[ecs]
+cluster = test
+region = primary
+
+[region_aliases]
+primary = us-east-1
+dr = us-west-2
+`
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	err = ioutil.WriteFile(dest.Path+"/"+configFileName, []byte(configContents), *dest.Mode)
+	assert.NoError(t, err)
+
+	parser := setupParser(t, dest, true)
+	readConfig, err := parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	expected := map[string]string{"primary": "us-east-1", "dr": "us-west-2"}
+	assert.Equal(t, expected, readConfig.RegionAliases, "Region aliases mismatch in config.")
+
+	// Re-running configure must not drop the aliases
+	saveConfigWithCluster(t, parser, dest)
+	parser = setupParser(t, dest, true)
+	readConfig, err = parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Equal(t, expected, readConfig.RegionAliases, "Region aliases should be preserved on save.")
+}
+
+func TestClusterCreationDefaults(t *testing.T) {
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	parser := setupParser(t, dest, false)
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	saveConfig(t, parser, dest, &SectionKeys{Cluster: testClusterName, KeyPairName: "my-key", InstanceType: "m4.large"})
+
+	parser = setupParser(t, dest, true)
+	readConfig, err := parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Equal(t, "my-key", readConfig.KeyPairName, "Key pair mismatch in config.")
+	assert.Equal(t, "m4.large", readConfig.InstanceType, "Instance type mismatch in config.")
+}
+
+func TestOutputPreferences(t *testing.T) {
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	parser := setupParser(t, dest, false)
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	saveConfig(t, parser, dest, &SectionKeys{Cluster: testClusterName, Color: ColorNever, Quiet: true})
+
+	parser = setupParser(t, dest, true)
+	readConfig, err := parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Equal(t, ColorNever, readConfig.Color, "Color mismatch in config.")
+	assert.True(t, readConfig.Quiet, "Quiet mismatch in config.")
+}
+
+func TestOutputPreferencesWithInvalidColor(t *testing.T) {
+	configContents := `[ecs]
+cluster = test
+color = sometimes
+`
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	err = ioutil.WriteFile(dest.Path+"/"+configFileName, []byte(configContents), *dest.Mode)
+	assert.NoError(t, err)
+
+	parser := setupParser(t, dest, true)
+	_, err = parser.GetConfig()
+	assert.Error(t, err, "Expected error for invalid color")
+}
+
+func TestAPITimeout(t *testing.T) {
+	dest, err := newMockDestination()
+	assert.NoError(t, err, "Error creating mock config destination")
+
+	parser := setupParser(t, dest, false)
+
+	err = os.MkdirAll(dest.Path, *dest.Mode)
+	assert.NoError(t, err, "Could not create config directory")
+
+	defer os.RemoveAll(dest.Path)
+
+	saveConfig(t, parser, dest, &SectionKeys{Cluster: testClusterName, APITimeout: "45s"})
+
+	parser = setupParser(t, dest, true)
+	readConfig, err := parser.GetConfig()
+	assert.NoError(t, err, "Error reading config")
+	assert.Equal(t, "45s", readConfig.APITimeout, "API timeout mismatch in config.")
+}
+
+func TestAPITimeoutWithInvalidValues(t *testing.T) {
+	for _, timeout := range []string{"-5s", "0s", "soon"} {
+		dest, err := newMockDestination()
+		assert.NoError(t, err, "Error creating mock config destination")
+
+		err = os.MkdirAll(dest.Path, *dest.Mode)
+		assert.NoError(t, err, "Could not create config directory")
+
+		configContents := "[ecs]\ncluster = test\napi_timeout = " + timeout + "\n"
+		err = ioutil.WriteFile(dest.Path+"/"+configFileName, []byte(configContents), *dest.Mode)
+		assert.NoError(t, err)
+
+		parser := setupParser(t, dest, true)
+		_, err = parser.GetConfig()
+		assert.Error(t, err, "Expected error for api_timeout %s", timeout)
+		os.RemoveAll(dest.Path)
+	}
+}
+
+func TestRegionFromNameWithInvalidPatterns(t *testing.T) {
+	for _, pattern := range []string{"app-(", "app-.*"} {
+		dest, err := newMockDestination()
+		assert.NoError(t, err, "Error creating mock config destination")
+
+		err = os.MkdirAll(dest.Path, *dest.Mode)
+		assert.NoError(t, err, "Could not create config directory")
+
+		// The pattern is not used when a region is set, but it must still be valid
+		configContents := "[ecs]\ncluster = test\nregion = us-west-2\nregion_from_name = " + pattern + "\n"
+		err = ioutil.WriteFile(dest.Path+"/"+configFileName, []byte(configContents), *dest.Mode)
+		assert.NoError(t, err)
+
+		parser := setupParser(t, dest, true)
+		_, err = parser.GetConfig()
+		assert.Error(t, err, "Expected error for region_from_name %s", pattern)
+		os.RemoveAll(dest.Path)
+	}
+}
+
 func TestConfigFileTruncation(t *testing.T) {
 	configContents := `[ecs]
 cluster = very-long-cluster-name
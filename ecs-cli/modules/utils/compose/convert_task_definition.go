@@ -33,6 +33,9 @@ const (
 	defaultMemLimit = 512
 	kiB             = 1024
 
+	// maximum number of containers allowed in a task definition by ECS
+	maxContainersPerTaskDefinition = 10
+
 	// access mode with which the volume is mounted
 	readOnlyVolumeAccessMode  = "ro"
 	readWriteVolumeAccessMode = "rw"
@@ -69,6 +72,10 @@ func ConvertToTaskDefinition(taskDefinitionName string, context *project.Context
 	if serviceConfigs.Len() == 0 {
 		return nil, errors.New("cannot create a task definition with no containers; invalid service config")
 	}
+	if serviceConfigs.Len() > maxContainersPerTaskDefinition {
+		return nil, fmt.Errorf("cannot create a task definition with more than %d containers; found %d: %s",
+			maxContainersPerTaskDefinition, serviceConfigs.Len(), strings.Join(serviceConfigs.Keys(), ", "))
+	}
 
 	logUnsupportedConfigFields(context.Project)
 
@@ -334,7 +341,9 @@ func convertToECSVolumes(hostPaths *volumes) []*ecs.Volume {
 	return output
 }
 
-// convertToPortMappings transforms the yml ports string slice to ecs compatible PortMappings slice
+// convertToPortMappings transforms the yml ports string slice to ecs compatible PortMappings slice.
+// Task definitions are registered with the default bridge network mode, so an omitted or zero
+// hostPort (Example "8000" or "0:8000") asks ECS to assign a dynamic host port.
 func convertToPortMappings(serviceName string, cfgPorts []string) ([]*ecs.PortMapping, error) {
 	portMappings := []*ecs.PortMapping{}
 	for _, portMapping := range cfgPorts {
@@ -357,15 +366,17 @@ func convertToPortMappings(serviceName string, cfgPorts []string) ([]*ecs.PortMa
 		case 1: // Format "containerPort" Example "8000"
 			containerPort, portErr = strconv.Atoi(parts[0])
 		case 2: // Format "hostPort:containerPort" Example "8000:8000"
-			hostPort, portErr = strconv.Atoi(parts[0])
-			containerPort, portErr = strconv.Atoi(parts[1])
+			if hostPort, portErr = strconv.Atoi(parts[0]); portErr == nil {
+				containerPort, portErr = strconv.Atoi(parts[1])
+			}
 		case 3: // Format "ipAddr:hostPort:containerPort" Example "127.0.0.0.1:8000:8000"
 			log.WithFields(log.Fields{
 				"container":   serviceName,
 				"portMapping": portMapping,
 			}).Warn("Ignoring the ip address while transforming it to task definition")
-			hostPort, portErr = strconv.Atoi(parts[1])
-			containerPort, portErr = strconv.Atoi(parts[2])
+			if hostPort, portErr = strconv.Atoi(parts[1]); portErr == nil {
+				containerPort, portErr = strconv.Atoi(parts[2])
+			}
 		default:
 			return nil, fmt.Errorf(
 				"expected format [hostPort]:containerPort. Could not parse portmappings: %s", portMapping)
@@ -127,6 +127,15 @@ func TestConvertToTaskDefinitionWithDnsSearch(t *testing.T) {
 	}
 }
 
+func TestConvertToTaskDefinitionWithMemReservationOnly(t *testing.T) {
+	serviceConfig := &config.ServiceConfig{MemReservation: yaml.MemStringorInt(int64(268435456))} // 256mb
+
+	taskDefinition := convertToTaskDefinitionInTest(t, "name", serviceConfig, "")
+	containerDef := *taskDefinition.ContainerDefinitions[0]
+	assert.Nil(t, containerDef.Memory, "Expected no hard memory limit")
+	assert.Equal(t, int64(256), aws.Int64Value(containerDef.MemoryReservation), "Expected memoryReservation to match")
+}
+
 func TestConvertToTaskDefinitionWithDnsServers(t *testing.T) {
 	dnsServer := "1.2.3.4"
 
@@ -345,6 +354,26 @@ func TestConvertToPortMappings(t *testing.T) {
 	verifyPortMapping(t, portMappingsOut[4], portNumber, portNumber, ecs.TransportProtocolTcp)
 }
 
+func TestConvertToPortMappingsWithDynamicHostPort(t *testing.T) {
+	portMappingsIn := []string{"0:8000"}
+
+	portMappingsOut, err := convertToPortMappings("test", portMappingsIn)
+	if err != nil {
+		t.Errorf("Expected to convert [%v] portMappings without errors. But got [%v]", portMappingsIn, err)
+	}
+	verifyPortMapping(t, portMappingsOut[0], 0, 8000, ecs.TransportProtocolTcp)
+}
+
+func TestConvertToPortMappingsWithInvalidHostPort(t *testing.T) {
+	// An unparseable host port must not silently fall back to a dynamic host port
+	portMappingsIn := []string{"invalid:8000"}
+
+	_, err := convertToPortMappings("test", portMappingsIn)
+	if err == nil {
+		t.Errorf("Expected error converting [%v] portMappings", portMappingsIn)
+	}
+}
+
 func verifyPortMapping(t *testing.T, output *ecs.PortMapping, hostPort, containerPort int64, protocol string) {
 	if protocol != *output.Protocol {
 		t.Errorf("Expected protocol [%s] But was [%s]", protocol, *output.Protocol)
@@ -644,6 +673,28 @@ func TestMemReservationHigherThanMemLimit(t *testing.T) {
 	assert.EqualError(t, err, "mem_limit should not be less than mem_reservation")
 }
 
+func TestConvertToTaskDefinitionWithMaxContainers(t *testing.T) {
+	serviceConfigs := config.NewServiceConfigs()
+	for i := 0; i < maxContainersPerTaskDefinition; i++ {
+		serviceConfigs.Add(fmt.Sprintf("container%d", i), &config.ServiceConfig{Image: "testimage"})
+	}
+
+	envLookup, err := GetDefaultEnvironmentLookup()
+	assert.NoError(t, err, "Unexpected error setting up environment lookup")
+	context := &project.Context{
+		Project:           &project.Project{},
+		EnvironmentLookup: envLookup,
+	}
+	taskDefinition, err := ConvertToTaskDefinition("ProjectName", context, serviceConfigs, "")
+	assert.NoError(t, err, "Unexpected error converting 10 containers")
+	assert.Len(t, taskDefinition.ContainerDefinitions, maxContainersPerTaskDefinition, "Expected all containers to be converted")
+
+	serviceConfigs.Add("container10", &config.ServiceConfig{Image: "testimage"})
+	_, err = ConvertToTaskDefinition("ProjectName", context, serviceConfigs, "")
+	assert.Error(t, err, "Expected error converting 11 containers")
+	assert.Contains(t, err.Error(), "container10", "Expected error to list the container names")
+}
+
 func TestSortedGoString(t *testing.T) {
 	family := aws.String("family1")
 	name := aws.String("foo")